@@ -3,7 +3,9 @@ package splox
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/url"
+	"time"
 )
 
 // BillingService provides methods for balance, cost tracking, and activity.
@@ -11,6 +13,25 @@ type BillingService struct {
 	client *Client
 }
 
+// microdollarsPerUSD is the number of microdollars in one US dollar.
+const microdollarsPerUSD = 1_000_000
+
+// MicrodollarsToUSD converts an amount in microdollars to US dollars.
+func MicrodollarsToUSD(m int64) float64 {
+	return float64(m) / microdollarsPerUSD
+}
+
+// USDToMicrodollars converts US dollars to microdollars, rounding to the
+// nearest microdollar (half away from zero).
+func USDToMicrodollars(usd float64) int64 {
+	return int64(math.Round(usd * microdollarsPerUSD))
+}
+
+// AmountUSD returns the transaction amount in US dollars.
+func (t *BalanceTransaction) AmountUSD() float64 {
+	return MicrodollarsToUSD(t.Amount)
+}
+
 // GetBalance returns the authenticated user's current balance.
 func (s *BillingService) GetBalance(ctx context.Context) (*UserBalance, error) {
 	var resp UserBalance
@@ -40,8 +61,8 @@ func (s *BillingService) GetTransactionHistory(ctx context.Context, params *Tran
 		if params.Page > 0 {
 			v.Set("page", fmt.Sprintf("%d", params.Page))
 		}
-		if params.Limit > 0 {
-			v.Set("limit", fmt.Sprintf("%d", params.Limit))
+		if err := setLimit(v, params.Limit); err != nil {
+			return nil, err
 		}
 		if params.Types != "" {
 			v.Set("types", params.Types)
@@ -73,6 +94,32 @@ func (s *BillingService) GetTransactionHistory(ctx context.Context, params *Tran
 	return &resp, nil
 }
 
+// GetTransaction returns a single balance transaction. It returns a
+// [*NotFoundError] if the ID is unknown.
+func (s *BillingService) GetTransaction(ctx context.Context, transactionID string) (*BalanceTransaction, error) {
+	var resp BalanceTransaction
+	if err := s.client.do(ctx, "GET", "/billing/transactions/"+transactionID, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetRequestCost returns the cost of a single workflow request with a
+// per-node breakdown. The API has no billing-by-request endpoint, so this is
+// computed from the request's execution tree using the cost and usage each
+// node reports; see [ExecutionNode.Cost] and [ExecutionNode.Tokens].
+func (s *BillingService) GetRequestCost(ctx context.Context, workflowRequestID string) (*RequestCost, error) {
+	resp, err := s.client.Workflows.GetExecutionTree(ctx, workflowRequestID)
+	if err != nil {
+		return nil, err
+	}
+	rc := resp.ExecutionTree.requestCost()
+	if rc.WorkflowRequestID == "" {
+		rc.WorkflowRequestID = workflowRequestID
+	}
+	return rc, nil
+}
+
 // GetActivityStats returns aggregate activity statistics (balance, total
 // requests, total spending, average cost per request, and token counts).
 func (s *BillingService) GetActivityStats(ctx context.Context) (*ActivityStats, error) {
@@ -86,8 +133,18 @@ func (s *BillingService) GetActivityStats(ctx context.Context) (*ActivityStats,
 // DailyActivityParams are optional parameters for [BillingService.GetDailyActivity].
 type DailyActivityParams struct {
 	Days int // number of days to look back (default 30)
+
+	// FillGaps makes the SDK add zero-value entries for days the server
+	// omitted, returning a contiguous series sorted ascending by Date.
+	FillGaps bool
 }
 
+// defaultActivityDays is the server's default look-back for daily activity.
+const defaultActivityDays = 30
+
+// dailyActivityDateLayout is the format of [DailyActivity.Date].
+const dailyActivityDateLayout = "2006-01-02"
+
 // GetDailyActivity returns daily aggregated spending and usage data.
 func (s *BillingService) GetDailyActivity(ctx context.Context, params *DailyActivityParams) (*DailyActivityResponse, error) {
 	v := url.Values{}
@@ -99,5 +156,53 @@ func (s *BillingService) GetDailyActivity(ctx context.Context, params *DailyActi
 	if err := s.client.do(ctx, "GET", addParams("/activity/daily", v), nil, &resp); err != nil {
 		return nil, err
 	}
+	if params != nil && params.FillGaps {
+		days := resp.Days
+		if days <= 0 {
+			days = params.Days
+		}
+		if days <= 0 {
+			days = defaultActivityDays
+		}
+		data, err := fillDailyGaps(resp.Data, days, time.Now().UTC())
+		if err != nil {
+			return nil, err
+		}
+		resp.Data = data
+	}
 	return &resp, nil
 }
+
+// fillDailyGaps returns data as a contiguous, ascending daily series covering
+// the days ending at today, plus any earlier or later dates the server
+// returned. Missing days get a zero-value entry.
+func fillDailyGaps(data []DailyActivity, days int, today time.Time) ([]DailyActivity, error) {
+	end := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -(days - 1))
+
+	byDate := make(map[string]DailyActivity, len(data))
+	for _, d := range data {
+		t, err := time.Parse(dailyActivityDateLayout, d.Date)
+		if err != nil {
+			return nil, fmt.Errorf("splox: parse daily activity date %q: %w", d.Date, err)
+		}
+		if t.Before(start) {
+			start = t
+		}
+		if t.After(end) {
+			end = t
+		}
+		byDate[t.Format(dailyActivityDateLayout)] = d
+	}
+
+	var series []DailyActivity
+	for t := start; !t.After(end); t = t.AddDate(0, 0, 1) {
+		date := t.Format(dailyActivityDateLayout)
+		d, ok := byDate[date]
+		if !ok {
+			d = DailyActivity{Date: date}
+		}
+		series = append(series, d)
+	}
+	return series, nil
+}
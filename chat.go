@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sync"
 )
 
 // ChatService provides methods for the Chats API.
@@ -11,22 +12,33 @@ type ChatService struct {
 	client *Client
 }
 
+// Chat resource types. A chat's resource type and ID identify what the chat
+// belongs to; [ChatService.ListForResource] must be called with the same type
+// the chat was created with.
+const (
+	// ResourceTypeAPI is used for chats created through the API (the default).
+	ResourceTypeAPI = "api"
+	// ResourceTypeWorkflow is used for chats created from the Splox app for a workflow.
+	ResourceTypeWorkflow = "workflow"
+)
+
 // CreateChatParams are the parameters for [ChatService.Create].
 type CreateChatParams struct {
 	Name         string         `json:"name"`
 	ResourceID   string         `json:"resource_id"`
-	ResourceType string         `json:"resource_type,omitempty"`
+	ResourceType string         `json:"resource_type,omitempty"` // defaults to the client's resource type
 	Metadata     map[string]any `json:"metadata,omitempty"`
 }
 
 // Create creates a new chat session.
-func (s *ChatService) Create(ctx context.Context, params CreateChatParams) (*Chat, error) {
+func (s *ChatService) Create(ctx context.Context, params CreateChatParams, opts ...RequestOption) (*Chat, error) {
 	if params.ResourceType == "" {
-		params.ResourceType = "api"
+		params.ResourceType = s.client.resourceType
 	}
+	params.Metadata = s.client.withContextMetadata(ctx, params.Metadata)
 
 	var resp Chat
-	if err := s.client.do(ctx, "POST", "/chats", params, &resp); err != nil {
+	if err := s.client.do(ctx, "POST", "/chats", params, &resp, opts...); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -41,21 +53,182 @@ func (s *ChatService) Get(ctx context.Context, chatID string) (*Chat, error) {
 	return &resp, nil
 }
 
-// ListForResource returns all chats for a given resource.
-func (s *ChatService) ListForResource(ctx context.Context, resourceType, resourceID string) (*ChatListResponse, error) {
+// ChatListParams are optional parameters for [ChatService.ListForResource].
+type ChatListParams struct {
+	Limit         int
+	Cursor        string
+	CreatedAfter  string // RFC3339 timestamp
+	CreatedBefore string // RFC3339 timestamp
+	Search        string // free-text match on chat names
+}
+
+// ListForResource returns a page of chats for a given resource. Without
+// params it returns the first page with the server's default page size; only
+// the first params value is used.
+// An empty resourceType uses the client's default (see [WithDefaultResourceType]).
+func (s *ChatService) ListForResource(ctx context.Context, resourceType, resourceID string, params ...*ChatListParams) (*ChatListResponse, error) {
+	if resourceType == "" {
+		resourceType = s.client.resourceType
+	}
+
+	v := url.Values{}
+	if len(params) > 0 && params[0] != nil {
+		params := params[0]
+		if err := setLimit(v, params.Limit); err != nil {
+			return nil, err
+		}
+		if params.Cursor != "" {
+			v.Set("cursor", params.Cursor)
+		}
+		if params.CreatedAfter != "" {
+			v.Set("created_after", params.CreatedAfter)
+		}
+		if params.CreatedBefore != "" {
+			v.Set("created_before", params.CreatedBefore)
+		}
+		if params.Search != "" {
+			v.Set("search", params.Search)
+		}
+	}
+
 	var resp ChatListResponse
-	if err := s.client.do(ctx, "GET", "/chats/"+resourceType+"/"+resourceID, nil, &resp); err != nil {
+	if err := s.client.do(ctx, "GET", addParams("/chats/"+resourceType+"/"+resourceID, v), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ChatIter iterates over chats across pages.
+// Call [ChatIter.Next] in a loop and check [ChatIter.Err] afterwards.
+type ChatIter struct {
+	p *pager[Chat]
+}
+
+// Next advances to the next chat.
+func (it *ChatIter) Next() bool { return it.p.next() }
+
+// Chat returns the current chat. Only valid after [ChatIter.Next] returns true.
+func (it *ChatIter) Chat() Chat { return it.p.cur }
+
+// Err returns any error encountered during iteration.
+func (it *ChatIter) Err() error { return it.p.err }
+
+// AllForResource returns an iterator over every chat for a resource,
+// following cursors until exhausted.
+func (s *ChatService) AllForResource(ctx context.Context, resourceType, resourceID string, params *ChatListParams) *ChatIter {
+	var p ChatListParams
+	if params != nil {
+		p = *params
+	}
+	return &ChatIter{p: newPager(ctx, func(ctx context.Context) ([]Chat, bool, error) {
+		resp, err := s.ListForResource(ctx, resourceType, resourceID, &p)
+		if err != nil {
+			return nil, false, err
+		}
+		p.Cursor = resp.PageCursor()
+		return resp.Chats, resp.HasNextPage(), nil
+	})}
+}
+
+// UpdateChatParams are the parameters for [ChatService.Update].
+// Only non-nil fields are sent.
+type UpdateChatParams struct {
+	Name     *string        `json:"name,omitempty"`
+	IsPublic *bool          `json:"is_public,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// Update changes a chat's name, visibility, or metadata and returns the updated chat.
+func (s *ChatService) Update(ctx context.Context, chatID string, params UpdateChatParams) (*Chat, error) {
+	var resp Chat
+	if err := s.client.do(ctx, "PATCH", "/chats/"+chatID, params, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
+// Share enables public sharing for a chat and returns it with a freshly
+// generated PublicShareToken. Calling Share on an already-shared chat rotates
+// the token.
+func (s *ChatService) Share(ctx context.Context, chatID string) (*Chat, error) {
+	var resp Chat
+	if err := s.client.do(ctx, "POST", "/chats/"+chatID+"/share", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Unshare disables public sharing for a chat. Any previously distributed
+// share link stops working immediately.
+func (s *ChatService) Unshare(ctx context.Context, chatID string) error {
+	return s.client.do(ctx, "DELETE", "/chats/"+chatID+"/share", nil, nil)
+}
+
+// SetPinnedVersion pins a chat to a specific workflow version so that
+// resumed conversations keep running against it even after the workflow is
+// updated. Pass an empty versionID to unpin and follow the latest version.
+func (s *ChatService) SetPinnedVersion(ctx context.Context, chatID, versionID string) error {
+	body := map[string]any{"workflow_version_id": nil}
+	if versionID != "" {
+		body["workflow_version_id"] = versionID
+	}
+	return s.client.do(ctx, "PUT", "/chats/"+chatID+"/pinned-version", body, nil)
+}
+
 // Listen opens an SSE stream for real-time chat events.
 // The caller must call [SSEIter.Close] when done.
 func (s *ChatService) Listen(ctx context.Context, chatID string) (*SSEIter, error) {
 	return s.client.streamSSE(ctx, "/chat-internal-messages/"+chatID+"/listen")
 }
 
+// Follow streams a chat together with the workflow requests it triggers.
+// It listens on the chat and, for each new workflow request announced on
+// it, also on that request's stream, interleaving all events in arrival
+// order. Events from a request stream that carry no RunID get the request's
+// ID as their RunID. The merged stream ends once the chat stream and every
+// request stream have ended; errors from any of them are joined in
+// [SSEIter.Err]. The caller must call [SSEIter.Close] when done.
+func (s *ChatService) Follow(ctx context.Context, chatID string) (*SSEIter, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	chatIter, err := s.Listen(ctx, chatID)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	f := newFanIn(ctx)
+	var mu sync.Mutex
+	followed := map[string]bool{}
+	f.add(chatIter, func(ev *SSEEvent) {
+		req := ev.WorkflowRequest
+		if req == nil || req.ID == "" || Status(req.Status).IsTerminal() {
+			return
+		}
+		mu.Lock()
+		seen := followed[req.ID]
+		followed[req.ID] = true
+		mu.Unlock()
+		if seen {
+			return
+		}
+
+		reqIter, err := s.client.Workflows.Listen(ctx, req.ID)
+		if err != nil {
+			f.fail(fmt.Errorf("splox: follow request %s: %w", req.ID, err))
+			return
+		}
+		requestID := req.ID
+		f.add(reqIter, func(ev *SSEEvent) {
+			if ev.RunID == "" {
+				ev.RunID = requestID
+			}
+		})
+	})
+	f.closeWhenDone()
+
+	return &SSEIter{ctx: ctx, cancel: cancel, merged: f}, nil
+}
+
 // Delete removes a chat session.
 func (s *ChatService) Delete(ctx context.Context, chatID string) error {
 	return s.client.do(ctx, "DELETE", "/chats/"+chatID, nil, nil)
@@ -63,16 +236,31 @@ func (s *ChatService) Delete(ctx context.Context, chatID string) error {
 
 // ChatHistoryParams are optional parameters for [ChatService.GetHistory].
 type ChatHistoryParams struct {
-	Limit  int
+	Limit  int    // 1-100; larger values are capped
 	Before string // RFC3339 timestamp for backward pagination
 }
 
 // GetHistory returns paginated chat message history.
 func (s *ChatService) GetHistory(ctx context.Context, chatID string, params *ChatHistoryParams) (*ChatHistoryResponse, error) {
+	return s.history(ctx, chatID, url.Values{}, params)
+}
+
+// SearchMessages returns the chat's messages whose content matches query,
+// paginated like GetHistory.
+func (s *ChatService) SearchMessages(ctx context.Context, chatID string, query string, params *ChatHistoryParams) (*ChatHistoryResponse, error) {
+	if query == "" {
+		return nil, &ValidationError{Field: "query", Message: "must not be empty"}
+	}
 	v := url.Values{}
+	v.Set("search", query)
+	return s.history(ctx, chatID, v, params)
+}
+
+// history fetches a page of chat history with v plus the values of params.
+func (s *ChatService) history(ctx context.Context, chatID string, v url.Values, params *ChatHistoryParams) (*ChatHistoryResponse, error) {
 	if params != nil {
-		if params.Limit > 0 {
-			v.Set("limit", fmt.Sprintf("%d", params.Limit))
+		if err := setLimit(v, params.Limit); err != nil {
+			return nil, err
 		}
 		if params.Before != "" {
 			v.Set("before", params.Before)
@@ -0,0 +1,127 @@
+package splox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Defaults for zero fields of [CircuitBreakerConfig].
+const (
+	DefaultCircuitFailureThreshold = 5
+	DefaultCircuitCooldown         = 30 * time.Second
+)
+
+// CircuitBreakerConfig configures [WithCircuitBreaker].
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures (connection
+	// errors and 5xx responses) that opens the circuit.
+	FailureThreshold int
+	// Cooldown is how long the circuit stays open before a single probe
+	// request is let through.
+	Cooldown time.Duration
+}
+
+// CircuitState is the state of a client's circuit breaker.
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // requests flow normally
+	CircuitOpen                         // requests fail fast with CircuitOpenError
+	CircuitHalfOpen                     // one probe request is in flight
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// circuitBreaker tracks consecutive failures and fails requests fast while
+// the upstream looks down. It is safe for concurrent use.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(cfg CircuitBreakerConfig) *circuitBreaker {
+	if cfg.FailureThreshold < 1 {
+		cfg.FailureThreshold = DefaultCircuitFailureThreshold
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = DefaultCircuitCooldown
+	}
+	return &circuitBreaker{threshold: cfg.FailureThreshold, cooldown: cfg.Cooldown}
+}
+
+// allow returns a [*CircuitOpenError] if the request must not be sent. Once
+// the cooldown has passed, the first caller becomes the half-open probe.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if wait := b.cooldown - time.Since(b.openedAt); wait > 0 {
+			return &CircuitOpenError{RetryAfter: wait}
+		}
+		b.state = CircuitHalfOpen
+		return nil
+	case CircuitHalfOpen:
+		return &CircuitOpenError{}
+	default:
+		return nil
+	}
+}
+
+// record updates the breaker with the outcome of a request let through by
+// allow. Upstream failures count against the circuit and any response from
+// the API closes it. Other errors, such as the caller cancelling, say nothing
+// about the upstream: they only hand the probe slot to the next request.
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case !errors.Is(err, context.Canceled) && isUpstreamFailure(err):
+		b.failures++
+		if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+			b.state = CircuitOpen
+			b.openedAt = time.Now()
+		}
+	case err == nil || statusOf(err) != 0:
+		b.state = CircuitClosed
+		b.failures = 0
+	case b.state == CircuitHalfOpen:
+		b.state = CircuitOpen
+	}
+}
+
+func (b *circuitBreaker) currentState() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// isUpstreamFailure reports whether err suggests the API itself is failing:
+// a transport error or a 5xx response.
+func isUpstreamFailure(err error) bool {
+	var connErr *ConnectionError
+	if errors.As(err, &connErr) {
+		return true
+	}
+	return statusOf(err) >= 500
+}
@@ -0,0 +1,116 @@
+package splox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTripsAndRecovers(t *testing.T) {
+	var healthy atomic.Bool
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`{"error":"upstream down"}`))
+			return
+		}
+		w.Write([]byte(`{"id":"chat-001"}`))
+	}))
+	defer srv.Close()
+	client := NewClient("key", WithBaseURL(srv.URL), WithCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		Cooldown:         50 * time.Millisecond,
+	}))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Chats.Get(ctx, "chat-001"); err == nil {
+			t.Fatal("expected 502 error")
+		}
+	}
+	if s := client.CircuitState(); s != CircuitOpen {
+		t.Fatalf("expected open circuit, got %s", s)
+	}
+
+	_, err := client.Chats.Get(ctx, "chat-001")
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) || openErr.RetryAfter <= 0 {
+		t.Errorf("expected CircuitOpenError with RetryAfter, got %v", err)
+	}
+	if !IsRetryable(err) {
+		t.Error("expected CircuitOpenError to be retryable")
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("expected open circuit to skip the server, got %d hits", n)
+	}
+
+	// A failed probe reopens the circuit.
+	time.Sleep(60 * time.Millisecond)
+	if _, err := client.Chats.Get(ctx, "chat-001"); errors.As(err, &openErr) {
+		t.Fatalf("expected probe to reach the server, got %v", err)
+	}
+	if s := client.CircuitState(); s != CircuitOpen {
+		t.Fatalf("expected circuit to reopen after failed probe, got %s", s)
+	}
+
+	// A successful probe closes it.
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+	if _, err := client.Chats.Get(ctx, "chat-001"); err != nil {
+		t.Fatal(err)
+	}
+	if s := client.CircuitState(); s != CircuitClosed {
+		t.Errorf("expected closed circuit, got %s", s)
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1})
+
+	b.record(&NotFoundError{APIError{StatusCode: 404}})
+	b.record(&ConnectionError{Err: context.Canceled})
+	if s := b.currentState(); s != CircuitClosed {
+		t.Errorf("expected 4xx and cancellation not to trip the circuit, got %s", s)
+	}
+
+	b.record(&ConnectionError{Err: errors.New("connection refused")})
+	if s := b.currentState(); s != CircuitOpen {
+		t.Errorf("expected connection error to trip the circuit, got %s", s)
+	}
+}
+
+func TestCircuitBreakerClientErrorClosesCircuit(t *testing.T) {
+	b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Millisecond})
+
+	// A 4xx between two 5xx resets the consecutive-failure count.
+	b.record(&APIError{StatusCode: 500})
+	b.record(&ConflictError{APIError{StatusCode: 409}})
+	b.record(&APIError{StatusCode: 500})
+	if s := b.currentState(); s != CircuitClosed {
+		t.Fatalf("expected 4xx to reset the failure count, got %s", s)
+	}
+
+	b.record(&APIError{StatusCode: 502})
+	if s := b.currentState(); s != CircuitOpen {
+		t.Fatalf("expected open circuit, got %s", s)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+
+	// The probe reached the API, so the circuit closes even on a 404.
+	b.record(&NotFoundError{APIError{StatusCode: 404}})
+	if s := b.currentState(); s != CircuitClosed {
+		t.Errorf("expected 4xx probe to close the circuit, got %s", s)
+	}
+	if err := b.allow(); err != nil {
+		t.Errorf("expected requests to flow after the probe, got %v", err)
+	}
+}
@@ -22,12 +22,32 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
+// Version is the SDK version, sent in the default User-Agent.
+const Version = "0.1.0"
+
 const (
-	DefaultBaseURL = "https://app.splox.io/api/v1"
-	DefaultTimeout = 30 * time.Second
+	DefaultBaseURL   = "https://app.splox.io/api/v1"
+	DefaultTimeout   = 30 * time.Second
+	DefaultUserAgent = "splox-go-sdk/" + Version
+
+	// DefaultStreamHeaderTimeout bounds how long an SSE stream waits for the
+	// server's response headers. The stream body itself is never timed out.
+	// Override it with WithStreamConnectTimeout.
+	DefaultStreamHeaderTimeout = 30 * time.Second
+
+	// DefaultMaxIdleConnsPerHost and DefaultIdleConnTimeout tune the
+	// connection pool of the client's default transport. All requests go to
+	// one host, so the per-host idle limit is raised well above net/http's 2.
+	DefaultMaxIdleConnsPerHost = 64
+	DefaultIdleConnTimeout     = 90 * time.Second
+
+	// DefaultMaxResponseBytes caps the decoded size of a non-streaming
+	// response body. Override it with WithMaxResponseBytes.
+	DefaultMaxResponseBytes = 32 << 20
 )
 
 // Client is the Splox API client.
@@ -40,32 +60,242 @@ type Client struct {
 	MCP       *MCPService
 	LLM       *LLMService
 
-	baseURL    string
-	apiKey     string
-	httpClient *http.Client
+	baseURL      string
+	pathPrefix   string
+	apiKey       string
+	credentials  func(ctx context.Context) (string, error)
+	httpClient   *http.Client
+	transport    http.RoundTripper // from WithTransport or WithRoundTripper; applied after all options
+	sseClient    *http.Client      // shared by all SSE streams; no overall timeout
+	resourceType string
+	userAgent    string
+	headers      map[string]string
+	responseTap  func(method, path string, status int, body []byte)
+	interceptor  func(*http.Request) error
+	logger       Logger
+	tracer       Tracer
+
+	metadataFromContext func(ctx context.Context) map[string]any
+
+	compressRequests     bool
+	useJSONNumber        bool
+	streamConnectTimeout time.Duration
+	defaultTimeout       time.Duration
+	maxResponseBytes     int64
+	limiter              *rateLimiter
+	breaker              *circuitBreaker
+}
+
+// Logger receives a record of every API call. Implementations must be safe
+// for concurrent use.
+type Logger interface {
+	// LogRequest is called before a request is sent.
+	LogRequest(ctx context.Context, method, path string)
+	// LogResponse is called once the call completes. status is 0 if no
+	// response was received; err is the error returned to the caller.
+	LogResponse(ctx context.Context, method, path string, status int, duration time.Duration, err error)
 }
 
 // Option configures the Client.
 type Option func(*Client)
 
+// WithAPIKey sets the API key, taking precedence over the key passed to
+// [NewClient]. An empty key is ignored.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		if key != "" {
+			c.apiKey = key
+		}
+	}
+}
+
+// WithCredentialProvider makes the client fetch the API key from fn for
+// every request and SSE stream instead of using a fixed key, e.g. to pick up
+// keys rotated by a secrets manager without restarting. fn takes precedence
+// over [WithAPIKey] and the key passed to [NewClient]. It is called once per
+// request, possibly from several goroutines at once; cache inside fn if
+// fetching is expensive. An error from fn fails the request.
+func WithCredentialProvider(fn func(ctx context.Context) (string, error)) Option {
+	return func(c *Client) { c.credentials = fn }
+}
+
 // WithBaseURL overrides the default API base URL.
 func WithBaseURL(url string) Option {
 	return func(c *Client) { c.baseURL = url }
 }
 
+// WithPathPrefix sets a route prefix inserted between the base URL and every
+// API path, for deployments that mount the API under a custom route (e.g.
+// "/splox/v1"). Leading and trailing slashes are normalized.
+func WithPathPrefix(prefix string) Option {
+	return func(c *Client) {
+		prefix = strings.Trim(prefix, "/")
+		if prefix != "" {
+			prefix = "/" + prefix
+		}
+		c.pathPrefix = prefix
+	}
+}
+
 // WithHTTPClient sets a custom *http.Client (e.g. for proxies or custom TLS).
+// Its transport is used as is, replacing the SDK's tuned default transport.
 func WithHTTPClient(hc *http.Client) Option {
 	return func(c *Client) { c.httpClient = hc }
 }
 
-// WithTimeout sets the HTTP request timeout.
+// WithTransport sets the transport used for all requests, including SSE
+// streams. It is applied after every other option, so it also replaces the
+// transport of a client given to [WithHTTPClient]; that client is copied, not
+// modified. Without it the SDK uses a clone of [http.DefaultTransport] with
+// HTTP/2 enabled, [DefaultMaxIdleConnsPerHost], and [DefaultIdleConnTimeout].
+func WithTransport(t *http.Transport) Option {
+	return func(c *Client) {
+		if t != nil {
+			c.transport = t
+		}
+	}
+}
+
+// WithRoundTripper is like [WithTransport] but accepts any
+// [http.RoundTripper], such as a sploxtest.MockTransport in unit tests or a
+// middleware wrapping another transport.
+func WithRoundTripper(rt http.RoundTripper) Option {
+	return func(c *Client) { c.transport = rt }
+}
+
+// WithTimeout sets the HTTP request timeout. It does not apply to SSE
+// streams, which stay open for as long as the server sends events.
 func WithTimeout(d time.Duration) Option {
 	return func(c *Client) { c.httpClient.Timeout = d }
 }
 
+// WithStreamConnectTimeout limits how long opening an SSE stream may take,
+// covering dial, TLS handshake, and response headers. Reading events from an
+// established stream is never timed out. Zero disables the limit.
+func WithStreamConnectTimeout(d time.Duration) Option {
+	return func(c *Client) { c.streamConnectTimeout = d }
+}
+
+// WithDefaultRequestTimeout bounds each API call whose ctx has no deadline by
+// d, so a slow call cannot hang the calling goroutine. A deadline already on
+// ctx is respected as is. Unlike [WithTimeout] it also bounds waiting for
+// [WithRateLimit]. SSE streams are not affected. Zero disables it.
+func WithDefaultRequestTimeout(d time.Duration) Option {
+	return func(c *Client) { c.defaultTimeout = d }
+}
+
+// WithMaxResponseBytes limits how many bytes of a response body are read,
+// after decompression. Larger responses fail with a [*ResponseTooLargeError].
+// SSE streams and [WorkflowService.StreamExecutionTree] are not limited. Zero
+// or a negative n disables the limit.
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) { c.maxResponseBytes = n }
+}
+
+// WithRateLimit throttles the client to rps requests per second with bursts
+// of up to burst requests, so it stays under server quotas instead of
+// reacting to 429s. Each API call and each SSE stream opened waits for a
+// token, honoring ctx. The limit is shared by all goroutines using the
+// client. A non-positive rps disables the limit.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		c.limiter = nil
+		if rps > 0 {
+			c.limiter = newRateLimiter(rps, burst)
+		}
+	}
+}
+
+// WithCircuitBreaker makes the client fail fast with a [*CircuitOpenError]
+// after cfg.FailureThreshold consecutive connection errors or 5xx responses.
+// After cfg.Cooldown one probe request is sent; its success closes the
+// circuit and its failure reopens it. SSE streams are not affected. Use
+// [Client.CircuitState] to export the state as a metric.
+func WithCircuitBreaker(cfg CircuitBreakerConfig) Option {
+	return func(c *Client) { c.breaker = newCircuitBreaker(cfg) }
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+// It defaults to [DefaultUserAgent].
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// WithDefaultHeaders sets extra headers sent with every request (e.g. an
+// API gateway's tenant header). They override the SDK's own headers, while
+// per-request headers override them.
+func WithDefaultHeaders(headers map[string]string) Option {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = make(map[string]string, len(headers))
+		}
+		for k, v := range headers {
+			c.headers[k] = v
+		}
+	}
+}
+
+// WithResponseTap registers a debugging hook that receives every raw API
+// response body, on success and on error, before it is decoded. The body
+// slice is a copy and may be retained.
+func WithResponseTap(tap func(method, path string, status int, body []byte)) Option {
+	return func(c *Client) { c.responseTap = tap }
+}
+
+// WithRequestInterceptor calls fn with every API request, including SSE
+// streams, just before it is sent, after auth and default headers are set.
+// fn may add headers, sign the request, or rewrite its URL. If fn returns an
+// error the request is not sent and the call fails with that error wrapped.
+// [Client.Notify], which calls third-party URLs, is not intercepted.
+func WithRequestInterceptor(fn func(*http.Request) error) Option {
+	return func(c *Client) { c.interceptor = fn }
+}
+
+// WithLogger sets a Logger that is called for every API request.
+func WithLogger(l Logger) Option {
+	return func(c *Client) { c.logger = l }
+}
+
+// WithTracer enables tracing: each API call runs in a span named
+// "splox.<METHOD> <path template>", and SSE streams get a span that ends
+// when the iterator is closed.
+func WithTracer(t Tracer) Option {
+	return func(c *Client) { c.tracer = t }
+}
+
+// WithRequestCompression gzips JSON request bodies larger than 1 KB and
+// sends them with Content-Encoding: gzip. It is off by default.
+func WithRequestCompression() Option {
+	return func(c *Client) { c.compressRequests = true }
+}
+
+// WithUseJSONNumber decodes JSON numbers inside untyped values (such as
+// ExecutionNode.OutputData or SSE tool results) as [json.Number] instead of
+// float64, so large integers keep their precision. Typed struct fields are
+// unaffected.
+func WithUseJSONNumber() Option {
+	return func(c *Client) { c.useJSONNumber = true }
+}
+
+// WithDefaultResourceType sets the resource type used by chat methods when the
+// caller leaves it empty. It defaults to [ResourceTypeAPI].
+func WithDefaultResourceType(resourceType string) Option {
+	return func(c *Client) { c.resourceType = resourceType }
+}
+
+// WithMetadataFromContext registers fn to supply metadata from the request
+// context (e.g. a tenant or trace ID). [WorkflowService.Run] merges it into
+// RunParams.AdditionalParams and [ChatService.Create] into
+// CreateChatParams.Metadata. Keys set by the caller take precedence; the
+// caller's map is never modified.
+func WithMetadataFromContext(fn func(ctx context.Context) map[string]any) Option {
+	return func(c *Client) { c.metadataFromContext = fn }
+}
+
 // NewClient creates a new Splox API client.
 //
-// If apiKey is empty, it falls back to the SPLOX_API_KEY environment variable.
+// The API key is resolved in this order: a non-empty [WithAPIKey] option,
+// then the apiKey argument, then the SPLOX_API_KEY environment variable.
 func NewClient(apiKey string, opts ...Option) *Client {
 	if apiKey == "" {
 		apiKey = os.Getenv("SPLOX_API_KEY")
@@ -75,13 +305,26 @@ func NewClient(apiKey string, opts ...Option) *Client {
 		baseURL: DefaultBaseURL,
 		apiKey:  apiKey,
 		httpClient: &http.Client{
-			Timeout: DefaultTimeout,
+			Timeout:   DefaultTimeout,
+			Transport: newDefaultTransport(),
 		},
+		resourceType: ResourceTypeAPI,
+		userAgent:    DefaultUserAgent,
+
+		streamConnectTimeout: DefaultStreamHeaderTimeout,
+		maxResponseBytes:     DefaultMaxResponseBytes,
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
+	if c.transport != nil {
+		hc := *c.httpClient
+		hc.Transport = c.transport
+		c.httpClient = &hc
+	}
+
+	c.sseClient = newStreamClient(c.httpClient.Transport, c.streamConnectTimeout)
 
 	c.Workflows = &WorkflowService{client: c}
 	c.Chats = &ChatService{client: c}
@@ -94,6 +337,59 @@ func NewClient(apiKey string, opts ...Option) *Client {
 	return c
 }
 
+// url returns the full URL for an API path, which must start with "/".
+func (c *Client) url(path string) string {
+	return strings.TrimSuffix(c.baseURL, "/") + c.pathPrefix + path
+}
+
+// withContextMetadata returns m merged over the metadata supplied by
+// [WithMetadataFromContext]. If there is nothing to merge, m is returned as
+// is; otherwise the result is a new map.
+func (c *Client) withContextMetadata(ctx context.Context, m map[string]any) map[string]any {
+	if c.metadataFromContext == nil {
+		return m
+	}
+	extra := c.metadataFromContext(ctx)
+	if len(extra) == 0 {
+		return m
+	}
+	merged := make(map[string]any, len(extra)+len(m))
+	for k, v := range extra {
+		merged[k] = v
+	}
+	for k, v := range m {
+		merged[k] = v
+	}
+	return merged
+}
+
+// CircuitState returns the state of the circuit breaker installed with
+// [WithCircuitBreaker], or [CircuitClosed] if there is none.
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.currentState()
+}
+
+// Ping verifies that the base URL is reachable and the API key is valid,
+// without side effects. It returns an [*AuthError] for a bad key and a
+// [*ConnectionError] if the API cannot be reached.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, "GET", "/billing/balance", nil, nil)
+}
+
+// Do sends a request to an endpoint the SDK has no typed method for yet. path
+// is relative to the base URL and may carry a query string. body, if non-nil,
+// is sent as JSON and the response is decoded into dst, if non-nil. Auth,
+// default headers, logging, tracing, and typed errors apply as for any other
+// call.
+//
+// Do is an unstable escape hatch: prefer the typed method once one exists.
+func (c *Client) Do(ctx context.Context, method, path string, body, dst any, opts ...RequestOption) error {
+	return c.do(ctx, method, path, body, dst, opts...)
+}
+
 // Notify POSTs data as JSON to webhookURL.
 func (c *Client) Notify(ctx context.Context, webhookURL string, data any) error {
 	body, err := json.Marshal(data)
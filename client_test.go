@@ -1,11 +1,25 @@
 package splox
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // newTestServer creates an httptest.Server that responds with the given status and body.
@@ -52,6 +66,100 @@ func TestWorkflowsList(t *testing.T) {
 	}
 }
 
+func TestWorkflowsListFilters(t *testing.T) {
+	var got url.Values
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		json.NewEncoder(w).Encode(WorkflowListResponse{})
+	})
+
+	if _, err := client.Workflows.List(context.Background(), &ListParams{}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Has("status") || got.Has("is_public") {
+		t.Errorf("unset filters should not be sent: %v", got)
+	}
+
+	public := false
+	if _, err := client.Workflows.List(context.Background(), &ListParams{Status: "published", IsPublic: &public}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("status") != "published" || got.Get("is_public") != "false" {
+		t.Errorf("unexpected query: %v", got)
+	}
+}
+
+func TestWorkflowsEstimateCost(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/workflow-requests/estimate" {
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+		var body RunParams
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.WorkflowVersionID == "ver-unknown" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"error":"cannot estimate workflow"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(CostEstimate{MinUSD: 0.01, MaxUSD: 0.05, InputTokens: 1200, OutputTokens: 300})
+	})
+
+	est, err := client.Workflows.EstimateCost(context.Background(), RunParams{WorkflowVersionID: "ver-001", Query: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if est.MinUSD != 0.01 || est.MaxUSD != 0.05 || est.InputTokens != 1200 {
+		t.Errorf("unexpected estimate: %+v", est)
+	}
+
+	_, err = client.Workflows.EstimateCost(context.Background(), RunParams{WorkflowVersionID: "ver-unknown"})
+	var unavailable *EstimateUnavailableError
+	if !errors.As(err, &unavailable) || unavailable.Message != "cannot estimate workflow" {
+		t.Errorf("expected EstimateUnavailableError, got %v", err)
+	}
+}
+
+func TestPageLimitClamp(t *testing.T) {
+	var got string
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query().Get("limit")
+		w.Write([]byte(`{}`))
+	})
+	ctx := context.Background()
+
+	if _, err := client.Workflows.List(ctx, &ListParams{Limit: 5000}); err != nil || got != "100" {
+		t.Errorf("Workflows.List: expected limit 100, got %q (err %v)", got, err)
+	}
+	if _, err := client.Chats.GetHistory(ctx, "chat-1", &ChatHistoryParams{Limit: 101}); err != nil || got != "100" {
+		t.Errorf("Chats.GetHistory: expected limit 100, got %q (err %v)", got, err)
+	}
+	if _, err := client.Memory.Get(ctx, "node-1", &MemoryGetParams{Limit: 20}); err != nil || got != "20" {
+		t.Errorf("Memory.Get: expected limit 20, got %q (err %v)", got, err)
+	}
+	if _, err := client.Workflows.GetHistory(ctx, "req-1", &HistoryParams{Limit: 500}); err != nil || got != "100" {
+		t.Errorf("Workflows.GetHistory: expected limit 100, got %q (err %v)", got, err)
+	}
+	if _, err := client.Workflows.SearchRequests(ctx, nil, &ListRequestsParams{Limit: 500}); err != nil || got != "100" {
+		t.Errorf("Workflows.SearchRequests: expected limit 100, got %q (err %v)", got, err)
+	}
+	if _, err := client.Chats.ListForResource(ctx, "api_trigger", "wf-1", &ChatListParams{Limit: 500}); err != nil || got != "100" {
+		t.Errorf("Chats.ListForResource: expected limit 100, got %q (err %v)", got, err)
+	}
+	if _, err := client.Billing.GetTransactionHistory(ctx, &TransactionHistoryParams{Limit: 500}); err != nil || got != "100" {
+		t.Errorf("Billing.GetTransactionHistory: expected limit 100, got %q (err %v)", got, err)
+	}
+
+	got = "unset"
+	_, err := client.Memory.List(ctx, "ver-1", &MemoryListParams{Limit: -1})
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) || valErr.Field != "Limit" {
+		t.Errorf("expected ValidationError for negative limit, got %v", err)
+	}
+	if got != "unset" {
+		t.Error("request should not be sent for a negative limit")
+	}
+}
+
 func TestWorkflowsGet(t *testing.T) {
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/workflows/wf-001" {
@@ -77,6 +185,219 @@ func TestWorkflowsGet(t *testing.T) {
 	}
 }
 
+func TestWorkflowsGetVersion(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/workflow-versions/ver-002" {
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(WorkflowFullResponse{
+			WorkflowVersion: WorkflowVersion{ID: "ver-002", WorkflowID: "wf-001", VersionNumber: 2},
+			Nodes:           []Node{{ID: "n-001", NodeType: NodeTypeStart}},
+		})
+	})
+
+	resp, err := client.Workflows.GetVersion(context.Background(), "ver-002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.WorkflowVersion.VersionNumber != 2 || len(resp.Nodes) != 1 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestWorkflowsDiffVersions(t *testing.T) {
+	x1, x2 := 1.0, 2.0
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/workflows/wf-001/versions":
+			json.NewEncoder(w).Encode(WorkflowVersionListResponse{Versions: []WorkflowVersion{
+				{ID: "ver-001", VersionNumber: 1}, {ID: "ver-002", VersionNumber: 2},
+			}})
+		case "/workflow-versions/ver-001":
+			json.NewEncoder(w).Encode(WorkflowFullResponse{
+				Nodes: []Node{
+					{ID: "n-1", WorkflowVersionID: "ver-001", Label: "Start"},
+					{ID: "n-2", WorkflowVersionID: "ver-001", Label: "Agent", PosX: &x1},
+					{ID: "n-3", WorkflowVersionID: "ver-001", Label: "Old"},
+				},
+				Edges: []Edge{{ID: "e-1", WorkflowVersionID: "ver-001", Source: "n-1", Target: "n-2"}},
+			})
+		case "/workflow-versions/ver-002":
+			json.NewEncoder(w).Encode(WorkflowFullResponse{
+				Nodes: []Node{
+					{ID: "n-1", WorkflowVersionID: "ver-002", Label: "Start"},
+					{ID: "n-2", WorkflowVersionID: "ver-002", Label: "Agent", PosX: &x2},
+					{ID: "n-4", WorkflowVersionID: "ver-002", Label: "New"},
+				},
+				Edges: []Edge{{ID: "e-1", WorkflowVersionID: "ver-002", Source: "n-1", Target: "n-2"}},
+			})
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	})
+
+	d, err := client.Workflows.DiffVersions(context.Background(), "wf-001", 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.AddedNodes) != 1 || d.AddedNodes[0].ID != "n-4" {
+		t.Errorf("unexpected added nodes: %+v", d.AddedNodes)
+	}
+	if len(d.RemovedNodes) != 1 || d.RemovedNodes[0].ID != "n-3" {
+		t.Errorf("unexpected removed nodes: %+v", d.RemovedNodes)
+	}
+	if len(d.ModifiedNodes) != 1 || d.ModifiedNodes[0].ID != "n-2" || *d.ModifiedNodes[0].After.PosX != 2 {
+		t.Errorf("unexpected modified nodes: %+v", d.ModifiedNodes)
+	}
+	if len(d.AddedEdges)+len(d.RemovedEdges)+len(d.ModifiedEdges) != 0 {
+		t.Errorf("expected no edge changes, got %+v", d)
+	}
+
+	_, err = client.Workflows.DiffVersions(context.Background(), "wf-001", 1, 9)
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) || valErr.Field != "toVersion" {
+		t.Errorf("expected ValidationError for missing version, got %v", err)
+	}
+}
+
+func TestDiffWorkflowsIgnoresEdgeTimestamps(t *testing.T) {
+	from := &WorkflowFullResponse{Edges: []Edge{{ID: "e-1", WorkflowVersionID: "ver-001", Source: "n-1", Target: "n-2",
+		CreatedAt: "2025-01-01T00:00:00Z", UpdatedAt: "2025-01-01T00:00:00Z"}}}
+	to := &WorkflowFullResponse{Edges: []Edge{{ID: "e-1", WorkflowVersionID: "ver-002", Source: "n-1", Target: "n-2",
+		CreatedAt: "2025-02-01T00:00:00Z", UpdatedAt: "2025-02-03T00:00:00Z"}}}
+
+	if d := diffWorkflows(from, to); !d.IsEmpty() {
+		t.Errorf("expected no changes for timestamp-only edge differences, got %+v", d)
+	}
+}
+
+func TestWorkflowFullResponseStartNodes(t *testing.T) {
+	resp := &WorkflowFullResponse{
+		Nodes: []Node{
+			{ID: "n-1", NodeType: NodeTypeAgent},
+			{ID: "n-2", NodeType: NodeTypeStart},
+			{ID: "n-3", NodeType: "tool"},
+			{ID: "n-4", NodeType: NodeTypeStart},
+		},
+	}
+	starts := resp.StartNodes()
+	if len(starts) != 2 || starts[0].ID != "n-2" || starts[1].ID != "n-4" {
+		t.Errorf("unexpected start nodes: %+v", starts)
+	}
+	if !starts[0].IsStart() || resp.Nodes[0].IsStart() {
+		t.Error("IsStart mismatch")
+	}
+}
+
+func TestNodeAgentConfig(t *testing.T) {
+	agent := Node{NodeType: NodeTypeAgent, Data: map[string]any{
+		"model":         "gpt-4o",
+		"system_prompt": "Be brief.",
+		"temperature":   0.2,
+		"max_tokens":    float64(512),
+		"custom":        true,
+	}}
+	cfg, ok := agent.AgentConfig()
+	if !ok {
+		t.Fatal("expected agent config")
+	}
+	if cfg.Model != "gpt-4o" || cfg.SystemPrompt != "Be brief." {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if cfg.Temperature == nil || *cfg.Temperature != 0.2 || cfg.MaxTokens == nil || *cfg.MaxTokens != 512 {
+		t.Errorf("unexpected sampling settings: %+v", cfg)
+	}
+
+	if _, ok := (Node{NodeType: NodeTypeStart, Data: agent.Data}).AgentConfig(); ok {
+		t.Error("expected ok=false for a start node")
+	}
+	if _, ok := (Node{NodeType: NodeTypeAgent, Data: map[string]any{"model": 42}}).AgentConfig(); ok {
+		t.Error("expected ok=false for mismatched data")
+	}
+
+	var custom struct{ Custom bool }
+	if err := agent.DecodeData(&custom); err != nil || !custom.Custom {
+		t.Errorf("expected DecodeData to read other keys, got %+v, %v", custom, err)
+	}
+}
+
+func TestWorkflowFullResponseMarshalCanonical(t *testing.T) {
+	x := 10.5
+	a := &WorkflowFullResponse{
+		Workflow: Workflow{ID: "wf-001"},
+		Nodes:    []Node{{ID: "n-2", PosX: &x}, {ID: "n-1", Data: map[string]any{"b": 1, "a": 2}}},
+		Edges:    []Edge{{ID: "e-2"}, {ID: "e-1"}},
+	}
+	b := &WorkflowFullResponse{
+		Workflow: Workflow{ID: "wf-001"},
+		Nodes:    []Node{{ID: "n-1", Data: map[string]any{"a": 2, "b": 1}}, {ID: "n-2", PosX: &x}},
+		Edges:    []Edge{{ID: "e-1"}, {ID: "e-2"}},
+	}
+
+	outA, err := a.MarshalCanonical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outB, err := b.MarshalCanonical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(outA) != string(outB) {
+		t.Errorf("expected identical output:\n%s\nvs\n%s", outA, outB)
+	}
+	if a.Nodes[0].ID != "n-2" {
+		t.Error("expected receiver to be left unsorted")
+	}
+}
+
+func TestWorkflowsExport(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/workflows/wf-001":
+			json.NewEncoder(w).Encode(WorkflowFullResponse{
+				Workflow:        Workflow{ID: "wf-001"},
+				WorkflowVersion: WorkflowVersion{ID: "ver-002", Name: "Support"},
+				Nodes:           []Node{{ID: "n-2"}, {ID: "n-1"}},
+				Edges:           []Edge{{ID: "e-1", Source: "n-1", Target: "n-2"}},
+			})
+		case r.Method == "GET" && r.URL.Path == "/workflows/wf-001/versions":
+			json.NewEncoder(w).Encode(WorkflowVersionListResponse{Versions: []WorkflowVersion{{ID: "ver-001"}, {ID: "ver-002"}}})
+		case r.Method == "GET" && r.URL.Path == "/workflow-versions/ver-001":
+			json.NewEncoder(w).Encode(WorkflowFullResponse{
+				Workflow:        Workflow{ID: "wf-001"},
+				WorkflowVersion: WorkflowVersion{ID: "ver-001", Name: "Support v1"},
+				Nodes:           []Node{{ID: "n-old"}},
+			})
+		default:
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	data, err := client.Workflows.Export(context.Background(), "wf-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bundle WorkflowBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		t.Fatal(err)
+	}
+	if bundle.SchemaVersion != WorkflowBundleSchemaVersion || len(bundle.Versions) != 2 {
+		t.Errorf("unexpected bundle: %+v", bundle)
+	}
+	if bundle.Workflow.WorkflowVersion.Name != "Support" {
+		t.Errorf("expected draft in bundle, got %+v", bundle.Workflow)
+	}
+	if len(bundle.Workflow.Nodes) != 2 || bundle.Workflow.Nodes[0].ID != "n-1" {
+		t.Errorf("expected sorted nodes in bundle, got %+v", bundle.Workflow.Nodes)
+	}
+	if v := bundle.Versions[0]; v.WorkflowVersion.ID != "ver-001" || len(v.Nodes) != 1 || v.Nodes[0].ID != "n-old" {
+		t.Errorf("expected published version graph in bundle, got %+v", v)
+	}
+	if v := bundle.Versions[1]; v.WorkflowVersion.ID != "ver-002" || len(v.Nodes) != 2 || len(v.Edges) != 1 {
+		t.Errorf("expected draft graph in bundle, got %+v", v)
+	}
+}
+
 func TestWorkflowsGetLatestVersion(t *testing.T) {
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/workflows/wf-001/versions/latest" {
@@ -115,6 +436,23 @@ func TestWorkflowsListVersions(t *testing.T) {
 	}
 }
 
+func TestWorkflowsListVersionsParams(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/workflows/wf-001/versions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("status") != "published" || q.Get("order") != "desc" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(WorkflowVersionListResponse{})
+	})
+
+	if _, err := client.Workflows.ListVersions(context.Background(), "wf-001", &ListVersionsParams{Status: "published", Order: "desc"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestWorkflowsGetEntryNodes(t *testing.T) {
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(EntryNodesResponse{
@@ -134,6 +472,22 @@ func TestWorkflowsGetEntryNodes(t *testing.T) {
 	}
 }
 
+func TestEntryNodesSingle(t *testing.T) {
+	one := &EntryNodesResponse{Nodes: []Node{{ID: "node-001", Label: "Agent"}}}
+	if n, err := one.Single(); err != nil || n.ID != "node-001" {
+		t.Errorf("expected node-001, got %v, %v", n, err)
+	}
+
+	var valErr *ValidationError
+	if _, err := (&EntryNodesResponse{}).Single(); !errors.As(err, &valErr) {
+		t.Errorf("expected ValidationError for no nodes, got %v", err)
+	}
+	two := &EntryNodesResponse{Nodes: []Node{{ID: "node-001", Label: "A"}, {ID: "node-002", Label: "B"}}}
+	if _, err := two.Single(); !errors.As(err, &valErr) || !strings.Contains(err.Error(), "node-002") {
+		t.Errorf("expected ValidationError listing both nodes, got %v", err)
+	}
+}
+
 func TestWorkflowsRun(t *testing.T) {
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
@@ -166,6 +520,66 @@ func TestWorkflowsRun(t *testing.T) {
 	}
 }
 
+func TestWorkflowsRetryChild(t *testing.T) {
+	retries := 0
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/workflow-requests/req-parent/execution-tree":
+			w.Write([]byte(`{"execution_tree":{"workflow_request_id":"req-parent","status":"completed","nodes":[
+				{"id":"en-1","node_label":"Fan out","status":"completed","child_executions":[
+					{"index":0,"workflow_request_id":"req-c0","status":"completed"},
+					{"index":1,"workflow_request_id":"req-c1","status":"failed"}]}]}}`))
+		case r.Method == "POST" && r.URL.Path == "/workflow-requests/req-parent/children/1/retry":
+			retries++
+			json.NewEncoder(w).Encode(RunResponse{WorkflowRequestID: "req-child-new"})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+	ctx := context.Background()
+
+	resp, err := client.Workflows.RetryChild(ctx, "req-parent", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.WorkflowRequestID != "req-child-new" {
+		t.Errorf("expected req-child-new, got %s", resp.WorkflowRequestID)
+	}
+
+	var valErr *ValidationError
+	for _, index := range []int{-1, 2} {
+		_, err = client.Workflows.RetryChild(ctx, "req-parent", index)
+		if !errors.As(err, &valErr) || valErr.Field != "childIndex" {
+			t.Errorf("index %d: expected ValidationError for childIndex, got %v", index, err)
+		}
+	}
+
+	_, err = client.Workflows.RetryChild(ctx, "req-parent", 0)
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Errorf("expected ConflictError for a completed child, got %v", err)
+	}
+	if retries != 1 {
+		t.Errorf("expected only the valid retry to reach the server, got %d", retries)
+	}
+}
+
+func TestCheckRetryableChildPartialList(t *testing.T) {
+	more, total := true, 500
+	parent := ExecutionTree{Nodes: []ExecutionNode{{
+		ChildExecutions: []ChildExecution{{Index: 0, Status: "failed"}},
+		HasMoreChildren: &more,
+		TotalChildren:   &total,
+	}}}
+	if err := checkRetryableChild(parent, 250); err != nil {
+		t.Errorf("expected an unlisted index within TotalChildren to be left to the server, got %v", err)
+	}
+	var valErr *ValidationError
+	if err := checkRetryableChild(parent, 500); !errors.As(err, &valErr) {
+		t.Errorf("expected ValidationError past TotalChildren, got %v", err)
+	}
+}
+
 func TestWorkflowsRunWithFiles(t *testing.T) {
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		var body RunParams
@@ -198,6 +612,92 @@ func TestWorkflowsRunWithFiles(t *testing.T) {
 	}
 }
 
+func TestWorkflowsRunLatest(t *testing.T) {
+	entryNodes := []Node{{ID: "node-001", Label: "Agent"}}
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/workflows/wf-001/versions":
+			if q := r.URL.Query(); q.Get("status") != "published" || q.Get("order") != "desc" {
+				t.Errorf("expected newest published versions first, got %s", r.URL.RawQuery)
+			}
+			json.NewEncoder(w).Encode(WorkflowVersionListResponse{Versions: []WorkflowVersion{
+				{ID: "ver-002", VersionNumber: 2, Status: "published", Name: "v2"},
+				{ID: "ver-001", VersionNumber: 1, Status: "published"},
+			}})
+		case "/workflows/ver-002/entry-nodes":
+			json.NewEncoder(w).Encode(EntryNodesResponse{Nodes: entryNodes})
+		case "/chats":
+			var body CreateChatParams
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.ResourceID != "wf-001" {
+				t.Errorf("expected chat for wf-001, got %+v", body)
+			}
+			json.NewEncoder(w).Encode(Chat{ID: "chat-new"})
+		case "/workflow-requests/run":
+			var body RunParams
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.WorkflowVersionID != "ver-002" || body.ChatID != "chat-new" || body.Query != "hi" ||
+				len(body.EntryNodeIDs) != 1 || body.EntryNodeIDs[0] != "node-001" {
+				t.Errorf("unexpected run params: %+v", body)
+			}
+			json.NewEncoder(w).Encode(RunResponse{WorkflowRequestID: "req-001"})
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	})
+
+	resp, err := client.Workflows.RunLatest(context.Background(), "wf-001", "hi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.WorkflowRequestID != "req-001" {
+		t.Errorf("expected req-001, got %s", resp.WorkflowRequestID)
+	}
+
+	entryNodes = append(entryNodes, Node{ID: "node-002", Label: "Other"})
+	var valErr *ValidationError
+	_, err = client.Workflows.RunLatest(context.Background(), "wf-001", "hi", WithChatID("chat-001"))
+	if !errors.As(err, &valErr) || !strings.Contains(valErr.Message, "node-002") {
+		t.Errorf("expected ValidationError naming the entry nodes, got %v", err)
+	}
+}
+
+func TestRunParamsRedacted(t *testing.T) {
+	p := RunParams{
+		WorkflowVersionID: "ver-001",
+		Query:             "my secret prompt",
+		AdditionalParams:  map[string]any{"api_token": "secret-value"},
+		Metadata:          map[string]any{"customer": "secret-customer"},
+		Files: []WorkflowRequestFile{{
+			URL:      "https://example.com/file.pdf?sig=secret-sig",
+			FileName: "report.pdf",
+			Metadata: map[string]any{"owner": "secret-owner"},
+		}},
+	}
+
+	r := p.Redacted()
+	if r.AdditionalParams["api_token"] != redactedText || r.Files[0].URL != redactedText {
+		t.Errorf("expected values to be redacted, got %+v", r)
+	}
+	if r.Metadata["customer"] != redactedText || r.Query != redactedText+" (16 chars)" {
+		t.Errorf("expected query and metadata to be redacted, got %+v", r)
+	}
+	if r.Files[0].FileName != "report.pdf" || r.WorkflowVersionID != "ver-001" {
+		t.Errorf("expected non-sensitive fields to be kept, got %+v", r)
+	}
+	if p.AdditionalParams["api_token"] != "secret-value" || p.Files[0].URL == redactedText {
+		t.Error("expected the original params to be left unmodified")
+	}
+
+	out := fmt.Sprintf("%+v", p)
+	if strings.Contains(out, "secret") {
+		t.Errorf("expected no secrets in formatted output, got %s", out)
+	}
+	if !strings.Contains(out, "api_token") || !strings.Contains(out, "customer") || !strings.Contains(out, "report.pdf") {
+		t.Errorf("expected keys and file names in formatted output, got %s", out)
+	}
+}
+
 func TestWorkflowsGetExecutionTree(t *testing.T) {
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(ExecutionTreeResponse{
@@ -250,6 +750,43 @@ func TestWorkflowsGetExecutionTree(t *testing.T) {
 	}
 }
 
+func TestWorkflowsStreamExecutionTree(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/workflow-requests/req-001/execution-tree" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"execution_tree":{"workflow_request_id":"req-001","status":"completed","meta":{"nested":[1,2]},"nodes":[{"id":"en-1","node_id":"n1","status":"completed"},{"id":"en-2","node_id":"n2","status":"failed"},{"id":"en-3","node_id":"n3","status":"completed"}]}}`))
+	})
+
+	var ids []string
+	err := client.Workflows.StreamExecutionTree(context.Background(), "req-001", func(n ExecutionNode) error {
+		ids = append(ids, n.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || ids[2] != "en-3" {
+		t.Errorf("expected 3 nodes, got %v", ids)
+	}
+
+	stop := errors.New("stop")
+	ids = nil
+	err = client.Workflows.StreamExecutionTree(context.Background(), "req-001", func(n ExecutionNode) error {
+		ids = append(ids, n.ID)
+		if n.Status == "failed" {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("expected fn error to be returned, got %v", err)
+	}
+	if len(ids) != 2 {
+		t.Errorf("expected streaming to stop after 2 nodes, got %v", ids)
+	}
+}
+
 func TestWorkflowsGetHistory(t *testing.T) {
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Query().Get("limit") != "5" {
@@ -278,73 +815,302 @@ func TestWorkflowsGetHistory(t *testing.T) {
 	}
 }
 
-func TestWorkflowsStop(t *testing.T) {
+func TestWorkflowsListNodeExecutions(t *testing.T) {
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != "POST" || r.URL.Path != "/workflow-requests/req-001/stop" {
-			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		if r.URL.Path != "/workflow-requests/req-001/node-executions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
 		}
-		w.WriteHeader(http.StatusNoContent)
+		q := r.URL.Query()
+		if q.Get("limit") != "2" || q.Get("cursor") != "ne-000" || q.Get("status") != "failed" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{
+			"node_executions": [
+				{"id": "ne-001", "node_id": "node-1", "status": "failed", "input_data": {"text": "hi"}, "output_data": {"error": "boom"}, "attempt_count": 3},
+				{"id": "ne-002", "node_id": "node-2", "status": "failed"}
+			],
+			"pagination": {"limit": 2, "next_cursor": "ne-002", "has_more": true}
+		}`))
 	})
 
-	err := client.Workflows.Stop(context.Background(), "req-001")
+	resp, err := client.Workflows.ListNodeExecutions(context.Background(), "req-001", &ListParams{
+		Limit:  2,
+		Cursor: "ne-000",
+		Status: "failed",
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
+	if len(resp.NodeExecutions) != 2 {
+		t.Fatalf("expected 2 node executions, got %d", len(resp.NodeExecutions))
+	}
+	ne := resp.NodeExecutions[0]
+	if ne.InputData["text"] != "hi" || ne.OutputData["error"] != "boom" {
+		t.Errorf("unexpected data: %+v", ne)
+	}
+	if ne.AttemptCount == nil || *ne.AttemptCount != 3 {
+		t.Errorf("expected attempt_count 3, got %v", ne.AttemptCount)
+	}
+	if !resp.HasNextPage() || resp.PageCursor() != "ne-002" {
+		t.Errorf("expected next page at ne-002, got %+v", resp.Pagination)
+	}
 }
 
-// --- Chat tests ---
-
-func TestChatsCreate(t *testing.T) {
+func TestWorkflowsSearchRequests(t *testing.T) {
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != "POST" || r.URL.Path != "/chats" {
-			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		if r.URL.Path != "/workflow-requests/search" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
 		}
-
-		var body CreateChatParams
-		json.NewDecoder(r.Body).Decode(&body)
-		if body.Name != "Test Chat" {
-			t.Errorf("expected name Test Chat, got %s", body.Name)
+		q := r.URL.Query()
+		if q.Get("metadata.customer_id") != "cust-42" {
+			t.Errorf("expected metadata.customer_id=cust-42, got %s", q.Get("metadata.customer_id"))
 		}
-		if body.ResourceType != "api" {
-			t.Errorf("expected resource_type api, got %s", body.ResourceType)
+		if q.Get("limit") != "10" {
+			t.Errorf("expected limit=10, got %s", q.Get("limit"))
 		}
-
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(Chat{
-			ID: "chat-001", Name: "Test Chat", ResourceType: "api", ResourceID: "wf-001",
+		json.NewEncoder(w).Encode(HistoryResponse{
+			Data: []WorkflowRequest{
+				{ID: "req-001", Status: "completed", Metadata: map[string]any{"customer_id": "cust-42"}},
+			},
 		})
 	})
 
-	chat, err := client.Chats.Create(context.Background(), CreateChatParams{
-		Name:       "Test Chat",
-		ResourceID: "wf-001",
-	})
+	resp, err := client.Workflows.SearchRequests(context.Background(), map[string]string{"customer_id": "cust-42"}, &ListRequestsParams{Limit: 10})
 	if err != nil {
 		t.Fatal(err)
 	}
-	if chat.ID != "chat-001" {
-		t.Errorf("expected chat-001, got %s", chat.ID)
-	}
-	if chat.Name != "Test Chat" {
-		t.Errorf("expected Test Chat, got %s", chat.Name)
+	if len(resp.Data) != 1 || resp.Data[0].ID != "req-001" {
+		t.Errorf("expected req-001, got %+v", resp.Data)
 	}
 }
 
-func TestChatsGet(t *testing.T) {
+func TestEmptySuccessBody(t *testing.T) {
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/chats/chat-001" {
-			t.Errorf("unexpected path: %s", r.URL.Path)
-		}
-		json.NewEncoder(w).Encode(Chat{
-			ID: "chat-001", Name: "Test Chat",
-		})
+		w.WriteHeader(http.StatusOK) // 200 with no body
 	})
 
+	if err := client.Workflows.Stop(context.Background(), "req-001"); err != nil {
+		t.Fatal(err)
+	}
 	chat, err := client.Chats.Get(context.Background(), "chat-001")
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("expected no error for empty 200 body, got %v", err)
 	}
-	if chat.ID != "chat-001" {
+	if chat.ID != "" {
+		t.Errorf("expected zero-valued chat, got %+v", chat)
+	}
+}
+
+func TestWorkflowsStopAll(t *testing.T) {
+	var mu sync.Mutex
+	var stopped []string
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/workflow-requests/search":
+			q := r.URL.Query()
+			if q.Get("workflow_version_id") != "ver-001" {
+				t.Errorf("expected version filter, got %s", r.URL.RawQuery)
+			}
+			if q.Get("status") != "in_progress" {
+				t.Errorf("expected status=in_progress, got %s", r.URL.RawQuery)
+			}
+			if q.Get("cursor") == "" {
+				json.NewEncoder(w).Encode(HistoryResponse{
+					Data:       []WorkflowRequest{{ID: "req-1", Status: "in_progress"}},
+					Pagination: Pagination{HasMore: true, NextCursor: "page-2"},
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(HistoryResponse{
+				Data: []WorkflowRequest{{ID: "req-3", Status: "in_progress"}, {ID: "req-4", Status: "in_progress"}},
+			})
+		case strings.HasSuffix(r.URL.Path, "/stop"):
+			id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/workflow-requests/"), "/stop")
+			if id == "req-4" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			mu.Lock()
+			stopped = append(stopped, id)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	n, err := client.Workflows.StopAll(context.Background(), "ver-001", WithConcurrency(2))
+	if n != 2 || len(stopped) != 2 {
+		t.Errorf("expected 2 stopped, got %d (%v)", n, stopped)
+	}
+	if err == nil || !strings.Contains(err.Error(), "req-4") {
+		t.Errorf("expected error for req-4, got %v", err)
+	}
+}
+
+func TestWorkflowsGetExecutionTrees(t *testing.T) {
+	var hits atomic.Int32
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/workflow-requests/"), "/execution-tree")
+		if id == "req-missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(ExecutionTreeResponse{
+			ExecutionTree: ExecutionTree{WorkflowRequestID: id, Status: "completed"},
+		})
+	})
+
+	ids := []string{"req-1", "req-missing", "req-2", "req-1"}
+	trees, err := client.Workflows.GetExecutionTrees(context.Background(), ids, WithConcurrency(2))
+	if len(trees) != 2 || trees["req-1"] == nil || trees["req-2"].ExecutionTree.WorkflowRequestID != "req-2" {
+		t.Errorf("expected trees for req-1 and req-2, got %v", trees)
+	}
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) || !strings.Contains(err.Error(), "req-missing") {
+		t.Errorf("expected NotFoundError for req-missing, got %v", err)
+	}
+	if n := hits.Load(); n != 3 {
+		t.Errorf("expected duplicate IDs to be fetched once, got %d requests", n)
+	}
+}
+
+func TestWorkflowsGetSecret(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("unexpected method: %s", r.Method)
+		}
+		if r.URL.Path != "/workflows/wf-001/secrets/API_KEY" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"secret not found"}`))
+			return
+		}
+		if r.URL.Query().Get("end_user_id") != "eu-1" {
+			t.Errorf("expected end_user_id=eu-1, got %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(WorkflowSecretMetadata{Key: "API_KEY", SecretType: "env", UpdatedAt: "2025-01-01T00:00:00Z"})
+	})
+
+	secret, err := client.Workflows.GetSecret(context.Background(), "wf-001", "API_KEY", &GetSecretParams{EndUserID: "eu-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Key != "API_KEY" || secret.UpdatedAt == "" {
+		t.Errorf("unexpected secret: %+v", secret)
+	}
+
+	_, err = client.Workflows.GetSecret(context.Background(), "wf-001", "MISSING", nil)
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestWorkflowsSetEnvSecrets(t *testing.T) {
+	var mu sync.Mutex
+	written := map[string]string{}
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/workflows/wf-001/secrets/env" {
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+		var body SetEnvSecretParams
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.EndUserID == nil || *body.EndUserID != "eu-1" {
+			t.Errorf("expected end_user_id eu-1, got %v", body.EndUserID)
+		}
+		if body.Key == "BAD" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid key"}`))
+			return
+		}
+		mu.Lock()
+		written[body.Key] = body.Value
+		mu.Unlock()
+		json.NewEncoder(w).Encode(SecretActionResponse{Success: true, Key: body.Key})
+	})
+
+	secrets := map[string]string{"API_KEY": "a", "DB_URL": "b", "BAD": "c"}
+	resp, err := client.Workflows.SetEnvSecrets(context.Background(), "wf-001", secrets, &SetSecretsParams{EndUserID: "eu-1"})
+	if err == nil || !strings.Contains(err.Error(), "BAD") {
+		t.Errorf("expected error for BAD, got %v", err)
+	}
+	if resp.Success || len(resp.Keys) != 2 || resp.Keys[0] != "API_KEY" || resp.Keys[1] != "DB_URL" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if written["API_KEY"] != "a" || written["DB_URL"] != "b" {
+		t.Errorf("unexpected writes: %v", written)
+	}
+}
+
+func TestWorkflowsStop(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/workflow-requests/req-001/stop" {
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	err := client.Workflows.Stop(context.Background(), "req-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// --- Chat tests ---
+
+func TestChatsCreate(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/chats" {
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+
+		var body CreateChatParams
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Name != "Test Chat" {
+			t.Errorf("expected name Test Chat, got %s", body.Name)
+		}
+		if body.ResourceType != "api" {
+			t.Errorf("expected resource_type api, got %s", body.ResourceType)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Chat{
+			ID: "chat-001", Name: "Test Chat", ResourceType: "api", ResourceID: "wf-001",
+		})
+	})
+
+	chat, err := client.Chats.Create(context.Background(), CreateChatParams{
+		Name:       "Test Chat",
+		ResourceID: "wf-001",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat.ID != "chat-001" {
+		t.Errorf("expected chat-001, got %s", chat.ID)
+	}
+	if chat.Name != "Test Chat" {
+		t.Errorf("expected Test Chat, got %s", chat.Name)
+	}
+}
+
+func TestChatsGet(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chats/chat-001" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(Chat{
+			ID: "chat-001", Name: "Test Chat",
+		})
+	})
+
+	chat, err := client.Chats.Get(context.Background(), "chat-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat.ID != "chat-001" {
 		t.Errorf("expected chat-001, got %s", chat.ID)
 	}
 }
@@ -371,6 +1137,90 @@ func TestChatsListForResource(t *testing.T) {
 	}
 }
 
+func TestChatsDefaultResourceType(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			var body CreateChatParams
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.ResourceType != ResourceTypeWorkflow {
+				t.Errorf("expected resource_type workflow, got %s", body.ResourceType)
+			}
+			json.NewEncoder(w).Encode(Chat{ID: "chat-001"})
+		case "GET":
+			if r.URL.Path != "/chats/workflow/wf-001" {
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+			json.NewEncoder(w).Encode(ChatListResponse{})
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient("key", WithBaseURL(srv.URL), WithDefaultResourceType(ResourceTypeWorkflow))
+	if _, err := client.Chats.Create(context.Background(), CreateChatParams{Name: "c", ResourceID: "wf-001"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Chats.ListForResource(context.Background(), "", "wf-001"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChatsListForResourceFilters(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("created_after") != "2025-01-01T00:00:00Z" || q.Get("created_before") != "2025-01-08T00:00:00Z" {
+			t.Errorf("unexpected date filters: %s", r.URL.RawQuery)
+		}
+		if q.Get("search") != "support" {
+			t.Errorf("expected search=support, got %s", q.Get("search"))
+		}
+		json.NewEncoder(w).Encode(ChatListResponse{})
+	})
+
+	_, err := client.Chats.ListForResource(context.Background(), "api", "wf-001", &ChatListParams{
+		CreatedAfter:  "2025-01-01T00:00:00Z",
+		CreatedBefore: "2025-01-08T00:00:00Z",
+		Search:        "support",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChatsListForResourcePagination(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limit") != "2" {
+			t.Errorf("expected limit=2, got %s", r.URL.Query().Get("limit"))
+		}
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			json.NewEncoder(w).Encode(ChatListResponse{Chats: []Chat{{ID: "c1"}, {ID: "c2"}}, NextCursor: "cur-1", HasMore: true})
+		case "cur-1":
+			json.NewEncoder(w).Encode(ChatListResponse{Chats: []Chat{{ID: "c3"}}})
+		}
+	})
+
+	page, err := client.Chats.ListForResource(context.Background(), "api", "wf-001", &ChatListParams{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !page.HasMore || page.NextCursor != "cur-1" {
+		t.Errorf("expected next cursor cur-1, got %+v", page)
+	}
+
+	iter := client.Chats.AllForResource(context.Background(), "api", "wf-001", &ChatListParams{Limit: 2})
+	count := 0
+	for iter.Next() {
+		count++
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 chats, got %d", count)
+	}
+}
+
 func TestChatsGetHistory(t *testing.T) {
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Query().Get("limit") != "10" {
@@ -408,6 +1258,51 @@ func TestChatsGetHistory(t *testing.T) {
 	}
 }
 
+func TestChatsSearchMessages(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat-history/chat-001/paginated" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("search") != "refund" || r.URL.Query().Get("limit") != "5" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(ChatHistoryResponse{
+			Messages: []ChatMessage{{ID: "msg-007", Content: []ChatMessageContent{{Type: "text", Text: "I want a refund"}}}},
+		})
+	})
+
+	resp, err := client.Chats.SearchMessages(context.Background(), "chat-001", "refund", &ChatHistoryParams{Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Messages) != 1 || resp.Messages[0].ID != "msg-007" {
+		t.Errorf("unexpected messages: %+v", resp.Messages)
+	}
+
+	var valErr *ValidationError
+	if _, err := client.Chats.SearchMessages(context.Background(), "chat-001", "", nil); !errors.As(err, &valErr) {
+		t.Errorf("expected ValidationError for empty query, got %v", err)
+	}
+}
+
+func TestChatMessageTextAndToolCalls(t *testing.T) {
+	msg := ChatMessage{
+		Content: []ChatMessageContent{
+			{Type: "reasoning", Reasoning: "thinking..."},
+			{Type: "text", Text: "Hello, "},
+			{Type: "tool-call", ToolCallID: "call-1", ToolName: "search"},
+			{Type: "text", Text: "world"},
+		},
+	}
+	if got := msg.Text(); got != "Hello, world" {
+		t.Errorf("expected Hello, world, got %q", got)
+	}
+	calls := msg.ToolCalls()
+	if len(calls) != 1 || calls[0].ToolName != "search" {
+		t.Errorf("expected one search tool call, got %+v", calls)
+	}
+}
+
 func TestChatsDelete(t *testing.T) {
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "DELETE" || r.URL.Path != "/chats/chat-001" {
@@ -422,6 +1317,86 @@ func TestChatsDelete(t *testing.T) {
 	}
 }
 
+func TestChatsUpdate(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/chats/chat-001" {
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["name"] != "Renamed" {
+			t.Errorf("expected name Renamed, got %v", body["name"])
+		}
+		if _, ok := body["is_public"]; ok {
+			t.Error("expected is_public to be omitted")
+		}
+		json.NewEncoder(w).Encode(Chat{ID: "chat-001", Name: "Renamed"})
+	})
+
+	name := "Renamed"
+	chat, err := client.Chats.Update(context.Background(), "chat-001", UpdateChatParams{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat.Name != "Renamed" {
+		t.Errorf("expected Renamed, got %s", chat.Name)
+	}
+}
+
+func TestChatsShareAndUnshare(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chats/chat-001/share" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		switch r.Method {
+		case "POST":
+			public := true
+			json.NewEncoder(w).Encode(Chat{ID: "chat-001", IsPublic: &public, PublicShareToken: "tok-new"})
+		case "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected method: %s", r.Method)
+		}
+	})
+
+	chat, err := client.Chats.Share(context.Background(), "chat-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat.PublicShareToken != "tok-new" {
+		t.Errorf("expected tok-new, got %s", chat.PublicShareToken)
+	}
+	if err := client.Chats.Unshare(context.Background(), "chat-001"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChatsSetPinnedVersion(t *testing.T) {
+	var got map[string]any
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/chats/chat-001/pinned-version" {
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+		got = nil
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	if err := client.Chats.SetPinnedVersion(context.Background(), "chat-001", "ver-001"); err != nil {
+		t.Fatal(err)
+	}
+	if got["workflow_version_id"] != "ver-001" {
+		t.Errorf("expected ver-001, got %v", got["workflow_version_id"])
+	}
+
+	if err := client.Chats.SetPinnedVersion(context.Background(), "chat-001", ""); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := got["workflow_version_id"]; !ok || v != nil {
+		t.Errorf("expected null workflow_version_id to unpin, got %v", v)
+	}
+}
+
 func TestChatsDeleteHistory(t *testing.T) {
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "DELETE" || r.URL.Path != "/chat-history/chat-001" {
@@ -438,6 +1413,59 @@ func TestChatsDeleteHistory(t *testing.T) {
 
 // --- Event tests ---
 
+func TestEventsSendTyped(t *testing.T) {
+	type orderPaid struct {
+		OrderID string `json:"order_id"`
+		Note    string `json:"note,omitempty"`
+	}
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/events/wh-001" {
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("X-Webhook-Secret") != "s3cret" {
+			t.Errorf("expected webhook secret header")
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"order_id":"o-1"}` {
+			t.Errorf("unexpected body: %s", body)
+		}
+		json.NewEncoder(w).Encode(EventResponse{OK: true, EventID: "evt-1"})
+	})
+
+	resp, err := client.Events.SendTyped(context.Background(), "wh-001", orderPaid{OrderID: "o-1"}, WithWebhookSecret("s3cret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.EventID != "evt-1" {
+		t.Errorf("expected evt-1, got %s", resp.EventID)
+	}
+}
+
+func TestEventsSendDryRun(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/events/wh-001" || r.URL.Query().Get("dry_run") != "true" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		if r.Header.Get("X-Webhook-Secret") != "s3cret" {
+			t.Errorf("expected webhook secret header")
+		}
+		json.NewEncoder(w).Encode(EventResponse{OK: true, DryRun: true})
+	})
+
+	resp, err := client.Events.Send(context.Background(), SendEventParams{
+		WebhookID: "wh-001",
+		Payload:   map[string]any{"order_id": 1},
+		Secret:    "s3cret",
+		DryRun:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.OK || !resp.DryRun {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
 func TestEventsSend(t *testing.T) {
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" || r.URL.Path != "/events/wh-001" {
@@ -487,16 +1515,1314 @@ func TestEventsSendWithSecret(t *testing.T) {
 	}
 }
 
-// --- Client config tests ---
+func TestEventsSendBatch(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["n"] == float64(2) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"boom"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(EventResponse{OK: true, EventID: fmt.Sprintf("evt-%v", body["n"])})
+	})
 
-func TestNewClientEnvFallback(t *testing.T) {
-	t.Setenv("SPLOX_API_KEY", "env-key")
-	client := NewClient("")
-	if client.apiKey != "env-key" {
+	payloads := []map[string]any{{"n": 0}, {"n": 1}, {"n": 2}, {"n": 3}}
+	results, err := client.Events.SendBatch(context.Background(), "wh-001", payloads, WithConcurrency(2))
+	if err == nil {
+		t.Fatal("expected combined error for failed item")
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 500 {
+		t.Errorf("expected wrapped 500 APIError, got %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	for i, want := range []string{"evt-0", "evt-1", "", "evt-3"} {
+		if results[i].EventID != want {
+			t.Errorf("result %d: expected %q, got %q", i, want, results[i].EventID)
+		}
+	}
+}
+
+func TestRunBounded(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	err := runBounded(context.Background(), 10, 3, func(i int) error {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		if i == 4 {
+			return errors.New("item 4 failed")
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "item 4 failed" {
+		t.Errorf("expected only item 4's error, got %v", err)
+	}
+	if p := peak.Load(); p > 3 {
+		t.Errorf("expected at most 3 calls in flight, got %d", p)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var started atomic.Int32
+	err = runBounded(ctx, 100, 1, func(i int) error {
+		if started.Add(1) == 2 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if n := started.Load(); n > 3 {
+		t.Errorf("expected no new calls after cancellation, got %d", n)
+	}
+}
+
+func signWebhook(payload []byte, secret string, ts int64) string {
+	timestamp := strconv.FormatInt(ts, 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	payload := []byte(`{"event_id":"evt-001"}`)
+	now := time.Now().Unix()
+
+	if err := VerifyWebhookSignature(payload, signWebhook(payload, "whsec", now), "whsec"); err != nil {
+		t.Errorf("expected valid signature, got %v", err)
+	}
+
+	var sigErr *SignatureError
+	err := VerifyWebhookSignature(payload, signWebhook(payload, "other", now), "whsec")
+	if !errors.As(err, &sigErr) {
+		t.Errorf("expected SignatureError for wrong secret, got %v", err)
+	}
+
+	stale := signWebhook(payload, "whsec", now-int64(time.Hour/time.Second))
+	if err := VerifyWebhookSignature(payload, stale, "whsec"); !errors.As(err, &sigErr) {
+		t.Errorf("expected SignatureError for stale timestamp, got %v", err)
+	}
+	if err := VerifyWebhookSignatureWithTolerance(payload, stale, "whsec", 0); err != nil {
+		t.Errorf("expected stale signature to pass with tolerance disabled, got %v", err)
+	}
+
+	if err := VerifyWebhookSignature(payload, "garbage", "whsec"); !errors.As(err, &sigErr) {
+		t.Errorf("expected SignatureError for malformed header, got %v", err)
+	}
+}
+
+// --- Billing tests ---
+
+func TestMicrodollarConversion(t *testing.T) {
+	if got := MicrodollarsToUSD(1_234_567); got != 1.234567 {
+		t.Errorf("expected 1.234567, got %v", got)
+	}
+	if got := USDToMicrodollars(0.29); got != 290_000 {
+		t.Errorf("expected 290000, got %d", got)
+	}
+	if got := USDToMicrodollars(-1.0000005); got != -1_000_001 {
+		t.Errorf("expected -1000001, got %d", got)
+	}
+	tx := BalanceTransaction{Amount: 2_500_000}
+	if got := tx.AmountUSD(); got != 2.5 {
+		t.Errorf("expected 2.5, got %v", got)
+	}
+}
+
+func TestBillingGetTransaction(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || !strings.HasPrefix(r.URL.Path, "/billing/transactions/") {
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Path != "/billing/transactions/tx-001" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"transaction not found"}`))
+			return
+		}
+		pi := "pi_123"
+		json.NewEncoder(w).Encode(BalanceTransaction{ID: "tx-001", Amount: 5_000_000, StripePaymentIntentID: &pi})
+	})
+
+	tx, err := client.Billing.GetTransaction(context.Background(), "tx-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.ID != "tx-001" || tx.StripePaymentIntentID == nil || *tx.StripePaymentIntentID != "pi_123" {
+		t.Errorf("unexpected transaction: %+v", tx)
+	}
+
+	_, err = client.Billing.GetTransaction(context.Background(), "tx-missing")
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestBillingGetRequestCost(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/workflow-requests/req-001/execution-tree" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(ExecutionTreeResponse{ExecutionTree: ExecutionTree{
+			WorkflowRequestID: "req-001",
+			Nodes: []ExecutionNode{
+				{NodeID: "start", NodeType: NodeTypeStart},
+				{
+					NodeID:     "agent",
+					NodeType:   NodeTypeAgent,
+					OutputData: map[string]any{"cost": 0.5, "usage": map[string]any{"input_tokens": 100, "output_tokens": 20}},
+					ChildExecutions: []ChildExecution{{Nodes: []ExecutionNode{
+						{NodeID: "sub", OutputData: map[string]any{"cost": 0.25}},
+					}}},
+				},
+			},
+		}})
+	})
+
+	rc, err := client.Billing.GetRequestCost(context.Background(), "req-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc.TotalCostUSD != 0.75 || rc.InputTokens != 100 || rc.OutputTokens != 20 {
+		t.Errorf("unexpected totals: %+v", rc)
+	}
+	if len(rc.Nodes) != 2 || rc.Nodes[0].NodeID != "agent" || rc.Nodes[1].NodeID != "sub" {
+		t.Errorf("unexpected breakdown: %+v", rc.Nodes)
+	}
+}
+
+func TestFillDailyGaps(t *testing.T) {
+	today := time.Date(2024, 3, 2, 15, 0, 0, 0, time.UTC)
+	data := []DailyActivity{
+		{Date: "2024-03-02", TotalCost: 2},
+		{Date: "2024-02-28", TotalCost: 1, RequestCount: 3},
+	}
+
+	got, err := fillDailyGaps(data, 4, today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"2024-02-28", "2024-02-29", "2024-03-01", "2024-03-02"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d days, got %+v", len(want), got)
+	}
+	for i, d := range got {
+		if d.Date != want[i] {
+			t.Errorf("day %d: expected %s, got %s", i, want[i], d.Date)
+		}
+	}
+	if got[0].RequestCount != 3 || got[1].TotalCost != 0 || got[3].TotalCost != 2 {
+		t.Errorf("unexpected values: %+v", got)
+	}
+
+	if _, err := fillDailyGaps([]DailyActivity{{Date: "March 1"}}, 4, today); err == nil {
+		t.Error("expected error for unparseable date")
+	}
+}
+
+// --- Memory tests ---
+
+func TestMemoryAppend(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/chat-memory/node-001/actions" {
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["action"] != "append" || body["role"] != "system" || body["content"] != "Customer tier: gold" {
+			t.Errorf("unexpected body: %v", body)
+		}
+		json.NewEncoder(w).Encode(MemoryActionResponse{
+			Action:   "append",
+			Messages: []MemoryMessage{{ID: "mem-001", Role: "system", Content: "Customer tier: gold"}},
+		})
+	})
+
+	msg, err := client.Memory.Append(context.Background(), "node-001", MemoryAppendParams{
+		ContextMemoryID:   "chat-001",
+		WorkflowVersionID: "ver-001",
+		Role:              "system",
+		Content:           "Customer tier: gold",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.ID != "mem-001" {
+		t.Errorf("expected mem-001, got %s", msg.ID)
+	}
+}
+
+func TestMemorySearch(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/chat-memory/node-001/actions" {
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["action"] != "search" || body["query"] != "shipping address" || body["top_k"] != float64(3) {
+			t.Errorf("unexpected body: %v", body)
+		}
+		w.Write([]byte(`{"results":[{"id":"mem-007","role":"user","content":"Ship to Berlin","score":0.91},{"id":"mem-002","role":"user","content":"Hi","score":0.12}]}`))
+	})
+
+	resp, err := client.Memory.Search(context.Background(), "node-001", MemorySearchParams{
+		ContextMemoryID:   "chat-001",
+		WorkflowVersionID: "ver-001",
+		Query:             "shipping address",
+		TopK:              3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 2 || resp.Results[0].ID != "mem-007" || resp.Results[0].Score != 0.91 {
+		t.Errorf("unexpected results: %+v", resp.Results)
+	}
+
+	var valErr *ValidationError
+	if _, err := client.Memory.Search(context.Background(), "node-001", MemorySearchParams{}); !errors.As(err, &valErr) {
+		t.Errorf("expected ValidationError for empty query, got %v", err)
+	}
+}
+
+func TestMemoryClearAll(t *testing.T) {
+	var cleared []string
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			if r.URL.Query().Get("cursor") == "" {
+				json.NewEncoder(w).Encode(MemoryListResponse{Chats: []MemoryInstance{
+					{ID: "m1", ChatID: "chat-1", MemoryNodeID: "node-1"},
+					{ID: "m2", ChatID: "chat-2", MemoryNodeID: "node-1"},
+				}, NextCursor: "c1", HasMore: true})
+				return
+			}
+			json.NewEncoder(w).Encode(MemoryListResponse{Chats: []MemoryInstance{{ID: "m3", ChatID: "chat-3", MemoryNodeID: "node-2"}}})
+			return
+		}
+
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["action"] != "clear" || body["workflow_version_id"] != "ver-001" {
+			t.Errorf("unexpected body: %v", body)
+		}
+		if body["context_memory_id"] == "chat-2" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"boom"}`))
+			return
+		}
+		node := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/chat-memory/"), "/actions")
+		cleared = append(cleared, node+":"+body["context_memory_id"].(string))
+		json.NewEncoder(w).Encode(MemoryActionResponse{Action: "clear"})
+	})
+
+	n, err := client.Memory.ClearAll(context.Background(), "ver-001")
+	if n != 2 {
+		t.Errorf("expected 2 cleared, got %d", n)
+	}
+	if err == nil || !strings.Contains(err.Error(), "m2") {
+		t.Errorf("expected error naming m2, got %v", err)
+	}
+	if len(cleared) != 2 || cleared[0] != "node-1:chat-1" || cleared[1] != "node-2:chat-3" {
+		t.Errorf("unexpected clears: %v", cleared)
+	}
+}
+
+func TestMemoryListAll(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limit") != "2" {
+			t.Errorf("expected limit=2, got %s", r.URL.Query().Get("limit"))
+		}
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			json.NewEncoder(w).Encode(MemoryListResponse{Chats: []MemoryInstance{{ID: "m1"}, {ID: "m2"}}, NextCursor: "c1", HasMore: true})
+		case "c1":
+			json.NewEncoder(w).Encode(MemoryListResponse{Chats: []MemoryInstance{{ID: "m3"}}})
+		default:
+			t.Errorf("unexpected cursor %s", r.URL.Query().Get("cursor"))
+		}
+	})
+
+	iter := client.Memory.ListAll(context.Background(), "ver-001", 2)
+	count := 0
+	for iter.Next() {
+		count++
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 instances, got %d", count)
+	}
+}
+
+func TestMemoryGetAll(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("chat_id") != "chat-001" {
+			t.Errorf("expected chat_id on every page, got %s", r.URL.Query().Get("chat_id"))
+		}
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			json.NewEncoder(w).Encode(MemoryGetResponse{Messages: []MemoryMessage{{ID: "a"}}, NextCursor: "c1", HasMore: true})
+		case "c1":
+			json.NewEncoder(w).Encode(MemoryGetResponse{Messages: []MemoryMessage{{ID: "b"}}})
+		}
+	})
+
+	iter := client.Memory.GetAll(context.Background(), "node-001", MemoryGetParams{ChatID: "chat-001"})
+	var ids []string
+	for iter.Next() {
+		ids = append(ids, iter.Message().ID)
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[1] != "b" {
+		t.Errorf("expected [a b], got %v", ids)
+	}
+}
+
+func TestMemoryExportFollowsCursor(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["action"] != "export" || body["context_memory_id"] != "chat-001" {
+			t.Errorf("unexpected body: %v", body)
+		}
+		switch body["cursor"] {
+		case nil:
+			json.NewEncoder(w).Encode(MemoryActionResponse{Action: "export", Messages: []MemoryMessage{{ID: "a"}}, NextCursor: "c1", HasMore: true})
+		case "c1":
+			json.NewEncoder(w).Encode(MemoryActionResponse{Action: "export", Messages: []MemoryMessage{{ID: "b"}, {ID: "c"}}})
+		default:
+			t.Errorf("unexpected cursor %v", body["cursor"])
+		}
+	})
+
+	resp, err := client.Memory.Export(context.Background(), "node-001", MemoryExportParams{
+		ContextMemoryID:   "chat-001",
+		WorkflowVersionID: "ver-001",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Messages) != 3 || resp.Messages[0].ID != "a" || resp.Messages[2].ID != "c" {
+		t.Errorf("expected all 3 messages in order, got %+v", resp.Messages)
+	}
+	if resp.HasMore || resp.NextCursor != "" {
+		t.Errorf("expected a complete export, got has_more=%v cursor=%q", resp.HasMore, resp.NextCursor)
+	}
+}
+
+func TestMemoryExportTo(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat-memory/node-001/actions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["action"] != "export" || body["context_memory_id"] != "chat-001" || body["workflow_version_id"] != "ver-001" {
+			t.Errorf("unexpected body: %v", body)
+		}
+		switch body["cursor"] {
+		case nil:
+			json.NewEncoder(w).Encode(MemoryActionResponse{Action: "export", Messages: []MemoryMessage{{ID: "a", Role: "user"}}, NextCursor: "c1", HasMore: true})
+		case "c1":
+			json.NewEncoder(w).Encode(MemoryActionResponse{Action: "export", Messages: []MemoryMessage{{ID: "b", Role: "assistant"}}})
+		default:
+			t.Errorf("unexpected cursor %v", body["cursor"])
+		}
+	})
+
+	var buf bytes.Buffer
+	err := client.Memory.ExportTo(context.Background(), "node-001", MemoryExportParams{
+		ContextMemoryID:   "chat-001",
+		WorkflowVersionID: "ver-001",
+	}, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), buf.String())
+	}
+	var msg MemoryMessage
+	if err := json.Unmarshal([]byte(lines[1]), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.ID != "b" {
+		t.Errorf("expected b, got %s", msg.ID)
+	}
+}
+
+// --- MCP tests ---
+
+func TestMCPAllCatalog(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("search") != "git" {
+			t.Errorf("expected search=git on every page, got %s", q.Get("search"))
+		}
+		var items []MCPCatalogItem
+		page, _ := strconv.Atoi(q.Get("page"))
+		switch page {
+		case 1:
+			items = []MCPCatalogItem{{ID: "a"}, {ID: "b"}}
+		case 2:
+			items = []MCPCatalogItem{{ID: "c"}}
+		default:
+			t.Errorf("unexpected page %s", q.Get("page"))
+		}
+		json.NewEncoder(w).Encode(MCPCatalogListResponse{MCPServers: items, CurrentPage: page, TotalPages: 2, TotalCount: 3})
+	})
+
+	iter := client.MCP.AllCatalog(context.Background(), &CatalogParams{Search: "git", PerPage: 2})
+	var ids []string
+	for iter.Next() {
+		ids = append(ids, iter.Item().ID)
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || ids[0] != "a" || ids[2] != "c" {
+		t.Errorf("expected [a b c], got %v", ids)
+	}
+}
+
+func TestMCPListCatalogFilters(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("category") != "developer" || q.Get("transport_type") != "http" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		category := "developer"
+		json.NewEncoder(w).Encode(MCPCatalogListResponse{
+			MCPServers: []MCPCatalogItem{{ID: "a", Category: &category, TransportType: "http"}},
+			TotalCount: 1,
+		})
+	})
+
+	resp, err := client.MCP.ListCatalog(context.Background(), &CatalogParams{Category: "developer", Transport: "http"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.MCPServers) != 1 || *resp.MCPServers[0].Category != "developer" {
+		t.Errorf("unexpected items: %+v", resp.MCPServers)
+	}
+}
+
+func TestMCPGetServerTools(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user-mcp-servers/srv-001/tools" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"options":[{"label":"Search","value":"search","description":"Search docs","input_schema":{"type":"object","properties":{"query":{"type":"string"}},"required":["query"]}}],"total":1,"limit":50}`))
+	})
+
+	resp, err := client.MCP.GetServerTools(context.Background(), "srv-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Options) != 1 {
+		t.Fatalf("expected 1 tool, got %d", len(resp.Options))
+	}
+	tool := resp.Options[0]
+	if tool.Description != "Search docs" {
+		t.Errorf("expected description, got %q", tool.Description)
+	}
+	if req := tool.RequiredArgs(); len(req) != 1 || req[0] != "query" {
+		t.Errorf("expected [query], got %v", req)
+	}
+}
+
+func TestMCPDeleteConnectionsForEndUser(t *testing.T) {
+	var deletedIDs []string
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/mcp-connections":
+			if r.URL.Query().Get("end_user_id") != "eu-1" {
+				t.Errorf("expected end_user_id filter, got %s", r.URL.RawQuery)
+			}
+			eu := "eu-1"
+			json.NewEncoder(w).Encode(MCPConnectionListResponse{Connections: []MCPConnection{
+				{ID: "c-1", EndUserID: &eu}, {ID: "c-2", EndUserID: &eu}, {ID: "c-3", EndUserID: &eu},
+			}})
+		case r.Method == "DELETE":
+			id := strings.TrimPrefix(r.URL.Path, "/mcp-connections/")
+			if id == "c-2" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			deletedIDs = append(deletedIDs, id)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	n, err := client.MCP.DeleteConnectionsForEndUser(context.Background(), "eu-1")
+	if n != 2 || len(deletedIDs) != 2 {
+		t.Errorf("expected 2 deletions, got %d (%v)", n, deletedIDs)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !strings.Contains(err.Error(), "c-2") {
+		t.Errorf("expected combined error for c-2, got %v", err)
+	}
+}
+
+func TestMCPExecuteToolResponseTextContent(t *testing.T) {
+	var resp MCPExecuteToolResponse
+	raw := `{"result":{"content":[{"type":"text","text":"Hello, "},{"type":"image","data":"..."},{"type":"text","text":"world"}],"isError":true},"is_error":false}`
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := resp.TextContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Hello, world" {
+		t.Errorf("expected %q, got %q", "Hello, world", text)
+	}
+	if !resp.Failed() {
+		t.Error("expected Failed from result isError")
+	}
+
+	bad := MCPExecuteToolResponse{Result: MCPExecuteToolResult{Content: []map[string]any{{"type": "text", "text": 42}}}}
+	if _, err := bad.TextContent(); err == nil {
+		t.Error("expected error for non-string text")
+	}
+}
+
+func TestMCPExecuteToolValidated(t *testing.T) {
+	toolFetches, executes := 0, 0
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user-mcp-servers/srv-001/tools":
+			toolFetches++
+			w.Write([]byte(`{"options":[{"label":"Search","value":"search","input_schema":{"type":"object","properties":{"query":{"type":"string"},"limit":{"type":"integer"}},"required":["query"]}}]}`))
+		case "/mcp-tools/execute":
+			executes++
+			json.NewEncoder(w).Encode(MCPExecuteToolResponse{})
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	})
+
+	ctx := context.Background()
+	var valErr *ValidationError
+
+	_, err := client.MCP.ExecuteToolValidated(ctx, ExecuteToolParams{MCPServerID: "srv-001", ToolSlug: "search", Args: map[string]any{"limit": 5}})
+	if !errors.As(err, &valErr) || valErr.Field != "query" {
+		t.Errorf("expected missing query ValidationError, got %v", err)
+	}
+
+	_, err = client.MCP.ExecuteToolValidated(ctx, ExecuteToolParams{MCPServerID: "srv-001", ToolSlug: "search", Args: map[string]any{"query": 42}})
+	if !errors.As(err, &valErr) || valErr.Field != "query" {
+		t.Errorf("expected wrong-type ValidationError, got %v", err)
+	}
+
+	_, err = client.MCP.ExecuteToolValidated(ctx, ExecuteToolParams{MCPServerID: "srv-001", ToolSlug: "search", Args: map[string]any{"query": "go", "limit": 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if toolFetches != 1 {
+		t.Errorf("expected tool schema to be fetched once, got %d", toolFetches)
+	}
+	if executes != 1 {
+		t.Errorf("expected 1 execute call, got %d", executes)
+	}
+}
+
+func TestValidateToolArgsGoTypes(t *testing.T) {
+	tool := MCPTool{InputSchema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"tags":   map[string]any{"type": "array"},
+			"labels": map[string]any{"type": "object"},
+			"filter": map[string]any{"type": "object"},
+			"ratio":  map[string]any{"type": "number"},
+			"count":  map[string]any{"type": "integer"},
+		},
+	}}
+	type filter struct {
+		Owner string `json:"owner"`
+	}
+
+	valid := map[string]any{
+		"tags":   []string{"a", "b"},
+		"labels": map[string]string{"env": "prod"},
+		"filter": filter{Owner: "me"},
+		"ratio":  float32(1.5),
+		"count":  uint8(3),
+	}
+	if err := validateToolArgs(tool, valid); err != nil {
+		t.Errorf("expected typed Go values to validate, got %v", err)
+	}
+	if err := validateToolArgs(tool, map[string]any{"count": 2.0, "ratio": int16(2)}); err != nil {
+		t.Errorf("expected whole floats to be integers, got %v", err)
+	}
+
+	var valErr *ValidationError
+	err := validateToolArgs(tool, map[string]any{"tags": filter{}})
+	if !errors.As(err, &valErr) || valErr.Field != "tags" || !strings.Contains(valErr.Message, "splox.filter") {
+		t.Errorf("expected struct to be rejected as array, got %v", err)
+	}
+	if err := validateToolArgs(tool, map[string]any{"count": 2.5}); !errors.As(err, &valErr) {
+		t.Errorf("expected fractional count to be rejected, got %v", err)
+	}
+}
+
+func TestMCPCreateConnection(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/mcp-connections" {
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+		var body CreateConnectionParams
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.MCPServerID != "srv-001" || body.EndUserID != "eu-001" {
+			t.Errorf("unexpected body: %+v", body)
+		}
+		if body.Credentials["api_key"] != "sk-123" {
+			t.Errorf("expected credentials api_key, got %v", body.Credentials["api_key"])
+		}
+		w.WriteHeader(http.StatusCreated)
+		endUser := "eu-001"
+		json.NewEncoder(w).Encode(MCPConnection{ID: "conn-001", Name: "GitHub", EndUserID: &endUser})
+	})
+
+	conn, err := client.MCP.CreateConnection(context.Background(), CreateConnectionParams{
+		MCPServerID: "srv-001",
+		EndUserID:   "eu-001",
+		Credentials: map[string]any{"api_key": "sk-123"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.ID != "conn-001" {
+		t.Errorf("expected conn-001, got %s", conn.ID)
+	}
+}
+
+func TestMCPGetConnection(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("expected GET, got %s", r.Method)
+		}
+		if r.URL.Path != "/mcp-connections/conn-001" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"connection not found"}`))
+			return
+		}
+		w.Write([]byte(`{"id":"conn-001","name":"GitHub","status":"expired","last_used_at":"2025-01-01T00:00:00Z"}`))
+	})
+
+	conn, err := client.MCP.GetConnection(context.Background(), "conn-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.Status != "expired" || conn.LastUsedAt != "2025-01-01T00:00:00Z" {
+		t.Errorf("unexpected connection: %+v", conn)
+	}
+
+	var notFound *NotFoundError
+	if _, err := client.MCP.GetConnection(context.Background(), "missing"); !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestMCPWaitForConnection(t *testing.T) {
+	minInterval, maxInterval := connectionPollMinInterval, connectionPollMaxInterval
+	connectionPollMinInterval, connectionPollMaxInterval = time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() { connectionPollMinInterval, connectionPollMaxInterval = minInterval, maxInterval })
+
+	var polls atomic.Int32
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("mcp_server_id") != "srv-001" || q.Get("end_user_id") != "eu-001" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		switch polls.Add(1) {
+		case 1:
+			w.Write([]byte(`{"connections":[]}`))
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":"try again"}`))
+		case 3:
+			w.Write([]byte(`{"connections":[{"id":"conn-001","status":"pending"}]}`))
+		default:
+			w.Write([]byte(`{"connections":[{"id":"conn-001","status":"connected"}]}`))
+		}
+	})
+
+	conn, err := client.MCP.WaitForConnection(context.Background(), "srv-001", "eu-001", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.ID != "conn-001" || polls.Load() != 4 {
+		t.Errorf("expected conn-001 after 4 polls, got %+v after %d", conn, polls.Load())
+	}
+}
+
+func TestMCPWaitForConnectionTimeout(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"connections":[]}`))
+	})
+
+	_, err := client.MCP.WaitForConnection(context.Background(), "srv-001", "eu-001", 50*time.Millisecond)
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Errorf("expected TimeoutError, got %v", err)
+	}
+}
+
+func TestMCPUserServers(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/user-mcp-servers":
+			var body CreateServerParams
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.URL != "https://mcp.example.com" {
+				t.Errorf("expected url, got %s", body.URL)
+			}
+			json.NewEncoder(w).Encode(UserMCPServer{ID: "srv-001", Name: body.Name, URL: body.URL})
+		case r.Method == "PATCH" && r.URL.Path == "/user-mcp-servers/srv-001":
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			if _, ok := body["url"]; ok {
+				t.Error("expected url to be omitted from update")
+			}
+			json.NewEncoder(w).Encode(UserMCPServer{ID: "srv-001", Name: body["name"].(string)})
+		case r.Method == "DELETE" && r.URL.Path == "/user-mcp-servers/srv-001":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	ctx := context.Background()
+	srv, err := client.MCP.CreateUserServer(ctx, CreateServerParams{Name: "Docs", URL: "https://mcp.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srv.ID != "srv-001" {
+		t.Errorf("expected srv-001, got %s", srv.ID)
+	}
+
+	name := "Docs v2"
+	srv, err = client.MCP.UpdateUserServer(ctx, "srv-001", UpdateServerParams{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srv.Name != "Docs v2" {
+		t.Errorf("expected Docs v2, got %s", srv.Name)
+	}
+
+	if err := client.MCP.DeleteUserServer(ctx, "srv-001"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPagerResponses(t *testing.T) {
+	drain := func(pages []Pager) (cursors []string) {
+		for _, p := range pages {
+			if !p.HasNextPage() {
+				break
+			}
+			cursors = append(cursors, p.PageCursor())
+		}
+		return cursors
+	}
+
+	got := drain([]Pager{
+		&WorkflowListResponse{Pagination: Pagination{HasMore: true, NextCursor: "c1"}},
+		&ChatListResponse{HasMore: true, NextCursor: "c2"},
+		&MemoryGetResponse{HasMore: true, NextCursor: "c3"},
+		&TransactionHistoryResponse{Pagination: TransactionPagination{Page: 2, HasNext: true}},
+		&MCPCatalogListResponse{CurrentPage: 1, TotalPages: 3},
+		&HistoryResponse{Pagination: Pagination{HasMore: true}}, // no cursor: stop
+		&MemoryListResponse{HasMore: true, NextCursor: "unreached"},
+	})
+	want := []string{"c1", "c2", "c3", "3", "2"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected cursors %v, got %v", want, got)
+	}
+}
+
+func TestParseConnectionToken(t *testing.T) {
+	token, err := GenerateConnectionToken("mcp-1", "owner-1", "user-1", "enc-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ParseConnectionToken(token, "enc-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.MCPServerID != "mcp-1" || claims.OwnerUserID != "owner-1" || claims.EndUserID != "user-1" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+	if d := claims.ExpiresAt.Sub(claims.IssuedAt); d != time.Hour {
+		t.Errorf("expected 1h lifetime, got %s", d)
+	}
+
+	var tokenErr *TokenError
+	if _, err := ParseConnectionToken(token, "other-key"); !errors.As(err, &tokenErr) {
+		t.Errorf("expected TokenError for wrong key, got %v", err)
+	}
+	if _, err := ParseConnectionToken("not-a-token", "enc-key"); !errors.As(err, &tokenErr) {
+		t.Errorf("expected TokenError for malformed token, got %v", err)
+	}
+}
+
+func TestParseConnectionTokenClockSkew(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	sign := func(claims map[string]interface{}) string {
+		claims["iss"] = mcpConnectionIssuer
+		token, err := signConnectionToken(claims, "enc-key")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	at := func(d time.Duration) int64 { return now.Add(d).Unix() }
+	cfg := tokenConfig{skew: time.Minute}
+
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		ok     bool
+	}{
+		{"exp just inside skew", map[string]interface{}{"iat": at(-time.Hour), "exp": at(-time.Minute)}, true},
+		{"exp past skew", map[string]interface{}{"iat": at(-time.Hour), "exp": at(-time.Minute - time.Second)}, false},
+		{"iat just inside skew", map[string]interface{}{"iat": at(time.Minute), "exp": at(time.Hour)}, true},
+		{"iat past skew", map[string]interface{}{"iat": at(time.Minute + time.Second), "exp": at(time.Hour)}, false},
+		{"nbf just inside skew", map[string]interface{}{"iat": at(0), "nbf": at(time.Minute), "exp": at(time.Hour)}, true},
+		{"nbf past skew", map[string]interface{}{"iat": at(0), "nbf": at(time.Minute + time.Second), "exp": at(time.Hour)}, false},
+		{"missing exp", map[string]interface{}{"iat": at(0)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseConnectionToken(sign(tt.claims), "enc-key", now, cfg)
+			if tt.ok && err != nil {
+				t.Errorf("expected token to be accepted, got %v", err)
+			}
+			var tokenErr *TokenError
+			if !tt.ok && !errors.As(err, &tokenErr) {
+				t.Errorf("expected TokenError, got %v", err)
+			}
+		})
+	}
+
+	// Without skew the exp boundary is exact.
+	token := sign(map[string]interface{}{"iat": at(-time.Hour), "exp": at(-time.Second)})
+	if _, err := parseConnectionToken(token, "enc-key", now, tokenConfig{}); err == nil {
+		t.Error("expected expired token to be rejected with zero skew")
+	}
+}
+
+// --- Client config tests ---
+
+func TestNewClientEnvFallback(t *testing.T) {
+	t.Setenv("SPLOX_API_KEY", "env-key")
+	client := NewClient("")
+	if client.apiKey != "env-key" {
 		t.Errorf("expected env-key, got %s", client.apiKey)
 	}
 }
 
+func TestWithAPIKeyPrecedence(t *testing.T) {
+	t.Setenv("SPLOX_API_KEY", "env-key")
+
+	if c := NewClient("", WithAPIKey("opt-key")); c.apiKey != "opt-key" {
+		t.Errorf("expected opt-key, got %s", c.apiKey)
+	}
+	if c := NewClient("arg-key", WithAPIKey("opt-key")); c.apiKey != "opt-key" {
+		t.Errorf("expected option to beat positional key, got %s", c.apiKey)
+	}
+	if c := NewClient("arg-key", WithAPIKey("")); c.apiKey != "arg-key" {
+		t.Errorf("expected empty option to be ignored, got %s", c.apiKey)
+	}
+	if c := NewClient("", WithAPIKey("")); c.apiKey != "env-key" {
+		t.Errorf("expected env fallback, got %s", c.apiKey)
+	}
+}
+
+func TestDefaultTransport(t *testing.T) {
+	c := NewClient("key")
+	tr, ok := c.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", c.httpClient.Transport)
+	}
+	if !tr.ForceAttemptHTTP2 || tr.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost || tr.IdleConnTimeout != DefaultIdleConnTimeout {
+		t.Errorf("unexpected transport settings: %+v", tr)
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	tr := &http.Transport{}
+	hc := &http.Client{Timeout: 5 * time.Second}
+
+	c := NewClient("key", WithTransport(tr), WithHTTPClient(hc))
+	if c.httpClient.Transport != tr {
+		t.Error("expected WithTransport to apply regardless of option order")
+	}
+	if c.httpClient.Timeout != 5*time.Second {
+		t.Errorf("expected settings of the given client to be kept, got %s", c.httpClient.Timeout)
+	}
+	if hc.Transport != nil {
+		t.Error("expected the caller's http.Client to be left unmodified")
+	}
+
+	if c := NewClient("key", WithHTTPClient(hc)); c.httpClient.Transport != nil {
+		t.Error("expected WithHTTPClient's transport to be used as is")
+	}
+}
+
+func TestWithMaxResponseBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id":"chat-001","name":"%s"}`, strings.Repeat("x", 100))
+	}))
+	defer srv.Close()
+
+	client := NewClient("key", WithBaseURL(srv.URL), WithMaxResponseBytes(64))
+	_, err := client.Chats.Get(context.Background(), "chat-001")
+	var tooLarge *ResponseTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != 64 {
+		t.Errorf("expected ResponseTooLargeError, got %v", err)
+	}
+
+	client = NewClient("key", WithBaseURL(srv.URL), WithMaxResponseBytes(1024))
+	if chat, err := client.Chats.Get(context.Background(), "chat-001"); err != nil || chat.ID != "chat-001" {
+		t.Errorf("expected response under the limit to decode, got %v, %v", chat, err)
+	}
+
+	if c := NewClient("key"); c.maxResponseBytes != DefaultMaxResponseBytes {
+		t.Errorf("expected default limit, got %d", c.maxResponseBytes)
+	}
+}
+
+func TestWithDefaultRequestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+		w.Write([]byte(`{"id":"chat-001"}`))
+	}))
+	defer srv.Close()
+	client := NewClient("key", WithBaseURL(srv.URL), WithDefaultRequestTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	_, err := client.Chats.Get(context.Background(), "chat-001")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded without a ctx deadline, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("expected the default timeout to cut the call short, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.Chats.Get(ctx, "chat-001"); err != nil {
+		t.Errorf("expected an existing deadline to be respected, got %v", err)
+	}
+}
+
+func TestWithRequestInterceptor(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.Header.Get("X-Signature") != "signed:Bearer key" {
+			t.Errorf("expected signature over the auth header, got %q", r.Header.Get("X-Signature"))
+		}
+		if r.URL.Path == "/proxy/listen" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintln(w, `data: {"type":"done"}`)
+			return
+		}
+		if r.URL.Path != "/proxy/chats/chat-001" {
+			t.Errorf("expected rewritten path, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"id":"chat-001"}`))
+	}))
+	defer srv.Close()
+
+	errDenied := errors.New("denied")
+	client := NewClient("key", WithBaseURL(srv.URL), WithRequestInterceptor(func(req *http.Request) error {
+		if strings.Contains(req.URL.Path, "forbidden") {
+			return errDenied
+		}
+		req.Header.Set("X-Signature", "signed:"+req.Header.Get("Authorization"))
+		if strings.HasSuffix(req.URL.Path, "/listen") {
+			req.URL.Path = "/proxy/listen"
+		} else {
+			req.URL.Path = "/proxy" + req.URL.Path
+		}
+		return nil
+	}))
+
+	if _, err := client.Chats.Get(context.Background(), "chat-001"); err != nil {
+		t.Fatal(err)
+	}
+	iter, err := client.Workflows.Listen(context.Background(), "req-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter.Close()
+
+	_, err = client.Chats.Get(context.Background(), "forbidden")
+	if !errors.Is(err, errDenied) {
+		t.Errorf("expected interceptor error, got %v", err)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("expected the failed interceptor to stop the request, got %d hits", n)
+	}
+}
+
+func TestClientDo(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/new-endpoint" || r.URL.Query().Get("mode") != "fast" {
+			t.Errorf("unexpected: %s %s", r.Method, r.URL)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-key" || r.Header.Get("X-Trace") != "abc" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["name"] != "x" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"no such thing"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	})
+
+	var out struct {
+		OK bool `json:"ok"`
+	}
+	err := client.Do(context.Background(), "POST", "/new-endpoint?mode=fast", map[string]any{"name": "x"}, &out, WithHeader("X-Trace", "abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.OK {
+		t.Error("expected ok=true")
+	}
+
+	var notFound *NotFoundError
+	err = client.Do(context.Background(), "POST", "/new-endpoint?mode=fast", map[string]any{}, nil, WithHeader("X-Trace", "abc"))
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestWithCredentialProvider(t *testing.T) {
+	var gotAuth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+		if r.Header.Get("Accept") == "text/event-stream" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			return
+		}
+		w.Write([]byte(`{"id":"chat-001"}`))
+	}))
+	defer srv.Close()
+
+	var calls atomic.Int32
+	provider := func(ctx context.Context) (string, error) {
+		return fmt.Sprintf("rotated-%d", calls.Add(1)), nil
+	}
+	client := NewClient("static-key", WithBaseURL(srv.URL), WithCredentialProvider(provider))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Chats.Get(context.Background(), "chat-001"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	iter, err := client.Chats.Listen(context.Background(), "chat-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter.Close()
+
+	want := []string{"Bearer rotated-1", "Bearer rotated-2", "Bearer rotated-3"}
+	if fmt.Sprint(gotAuth) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, gotAuth)
+	}
+
+	failing := NewClient("static-key", WithBaseURL(srv.URL), WithCredentialProvider(func(ctx context.Context) (string, error) {
+		return "", errors.New("vault sealed")
+	}))
+	if _, err := failing.Chats.Get(context.Background(), "chat-001"); err == nil || !strings.Contains(err.Error(), "vault sealed") {
+		t.Errorf("expected provider error, got %v", err)
+	}
+	if len(gotAuth) != 3 {
+		t.Errorf("expected no request when the provider fails, got %d", len(gotAuth))
+	}
+}
+
+func TestPing(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/billing/balance" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			w.WriteHeader(401)
+			w.Write([]byte(`{"error":"Invalid token"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(UserBalance{Currency: "USD"})
+	})
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := NewClient("bad-key", WithBaseURL(client.baseURL))
+	var authErr *AuthError
+	if err := bad.Ping(context.Background()); !errors.As(err, &authErr) {
+		t.Errorf("expected AuthError, got %v", err)
+	}
+
+	down := NewClient("key", WithBaseURL("http://127.0.0.1:1"))
+	var connErr *ConnectionError
+	if err := down.Ping(context.Background()); !errors.As(err, &connErr) {
+		t.Errorf("expected ConnectionError, got %v", err)
+	}
+}
+
+func TestWithUseJSONNumber(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"execution_tree":{"nodes":[{"id":"ne-1","output_data":{"record_id":12345678901234567}}]}}`))
+	})
+
+	resp, err := client.Workflows.GetExecutionTree(context.Background(), "req-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := resp.ExecutionTree.Nodes[0].OutputData["record_id"].(float64); !ok {
+		t.Errorf("expected float64 by default, got %T", resp.ExecutionTree.Nodes[0].OutputData["record_id"])
+	}
+
+	precise := NewClient("test-key", WithBaseURL(client.baseURL), WithUseJSONNumber())
+	resp, err = precise.Workflows.GetExecutionTree(context.Background(), "req-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, ok := resp.ExecutionTree.Nodes[0].OutputData["record_id"].(json.Number)
+	if !ok || n.String() != "12345678901234567" {
+		t.Errorf("expected json.Number 12345678901234567, got %T %v", resp.ExecutionTree.Nodes[0].OutputData["record_id"], n)
+	}
+}
+
+func TestWithResultMeta(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "rid-1")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Chat{ID: "chat-001"})
+	})
+
+	var meta ResultMeta
+	if _, err := client.Chats.Create(context.Background(), CreateChatParams{Name: "c"}, WithResultMeta(&meta)); err != nil {
+		t.Fatal(err)
+	}
+	if meta.StatusCode != http.StatusCreated {
+		t.Errorf("expected 201, got %d", meta.StatusCode)
+	}
+	if meta.Headers.Get("X-Request-ID") != "rid-1" {
+		t.Errorf("expected X-Request-ID header, got %v", meta.Headers)
+	}
+}
+
+func TestWithPathPrefix(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path+"?"+r.URL.RawQuery)
+		if strings.HasSuffix(r.URL.Path, "/listen") {
+			w.Header().Set("Content-Type", "text/event-stream")
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient("key", WithBaseURL(srv.URL+"/"), WithPathPrefix("/splox/v1/"))
+	ctx := context.Background()
+	if _, err := client.Workflows.List(ctx, &ListParams{Search: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Events.Send(ctx, SendEventParams{WebhookID: "wh-1"}); err != nil {
+		t.Fatal(err)
+	}
+	iter, err := client.Workflows.Listen(ctx, "req-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter.Close()
+
+	want := []string{"/splox/v1/workflows?search=x", "/splox/v1/events/wh-1?", "/splox/v1/workflow-requests/req-1/listen?"}
+	if len(paths) != len(want) {
+		t.Fatalf("expected %d requests, got %v", len(want), paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("request %d: expected %s, got %s", i, want[i], paths[i])
+		}
+	}
+}
+
+type tenantKey struct{}
+
+func TestWithMetadataFromContext(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/workflow-requests/run":
+			got, _ = body["additional_params"].(map[string]any)
+			json.NewEncoder(w).Encode(RunResponse{WorkflowRequestID: "req-1"})
+		case "/chats":
+			got, _ = body["metadata"].(map[string]any)
+			json.NewEncoder(w).Encode(Chat{ID: "chat-1"})
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient("key", WithBaseURL(srv.URL), WithMetadataFromContext(func(ctx context.Context) map[string]any {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return map[string]any{"tenant_id": tenant, "source": "ctx"}
+	}))
+	ctx := context.WithValue(context.Background(), tenantKey{}, "t-42")
+
+	params := map[string]any{"source": "caller"}
+	if _, err := client.Workflows.Run(ctx, RunParams{WorkflowVersionID: "ver-1", AdditionalParams: params}); err != nil {
+		t.Fatal(err)
+	}
+	if got["tenant_id"] != "t-42" || got["source"] != "caller" {
+		t.Errorf("unexpected additional_params: %v", got)
+	}
+	if len(params) != 1 {
+		t.Errorf("caller's map was modified: %v", params)
+	}
+
+	if _, err := client.Chats.Create(ctx, CreateChatParams{Name: "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if got["tenant_id"] != "t-42" || got["source"] != "ctx" {
+		t.Errorf("unexpected chat metadata: %v", got)
+	}
+}
+
 func TestCustomBaseURL(t *testing.T) {
 	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(Chat{ID: "chat-001", Name: "Test"})
@@ -510,3 +2836,221 @@ func TestCustomBaseURL(t *testing.T) {
 		t.Errorf("expected chat-001, got %s", chat.ID)
 	}
 }
+
+func TestUserAgent(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+		json.NewEncoder(w).Encode(Chat{ID: "chat-001"})
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient("key", WithBaseURL(srv.URL)).Chats.Get(context.Background(), "chat-001"); err != nil {
+		t.Fatal(err)
+	}
+	if got != DefaultUserAgent {
+		t.Errorf("expected %s, got %s", DefaultUserAgent, got)
+	}
+
+	if _, err := NewClient("key", WithBaseURL(srv.URL), WithUserAgent("acme/2.0")).Chats.Get(context.Background(), "chat-001"); err != nil {
+		t.Fatal(err)
+	}
+	if got != "acme/2.0" {
+		t.Errorf("expected acme/2.0, got %s", got)
+	}
+}
+
+func TestDefaultHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Tenant-ID") != "tenant-1" {
+			t.Errorf("expected X-Tenant-ID tenant-1, got %q", r.Header.Get("X-Tenant-ID"))
+		}
+		if r.URL.Path == "/events/wh-001" && r.Header.Get("X-Webhook-Secret") != "per-request" {
+			t.Errorf("expected per-request header to win, got %q", r.Header.Get("X-Webhook-Secret"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		json.NewEncoder(w).Encode(EventResponse{OK: true})
+	}))
+	defer srv.Close()
+
+	client := NewClient("key", WithBaseURL(srv.URL), WithDefaultHeaders(map[string]string{
+		"X-Tenant-ID":      "tenant-1",
+		"X-Webhook-Secret": "default",
+	}))
+	ctx := context.Background()
+
+	if _, err := client.Events.Send(ctx, SendEventParams{WebhookID: "wh-001", Secret: "per-request"}); err != nil {
+		t.Fatal(err)
+	}
+	iter, err := client.Chats.Listen(ctx, "chat-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter.Close()
+}
+
+func TestRequestOptionWithHeader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Debug") != "true" {
+			t.Errorf("expected X-Debug true, got %q", r.Header.Get("X-Debug"))
+		}
+		if r.Header.Get("X-Tenant-ID") != "override" {
+			t.Errorf("expected per-request header to override default, got %q", r.Header.Get("X-Tenant-ID"))
+		}
+		json.NewEncoder(w).Encode(RunResponse{WorkflowRequestID: "req-001"})
+	}))
+	defer srv.Close()
+
+	client := NewClient("key", WithBaseURL(srv.URL), WithDefaultHeaders(map[string]string{"X-Tenant-ID": "default"}))
+	_, err := client.Workflows.Run(context.Background(), RunParams{Query: "hi"},
+		WithHeader("X-Debug", "true"),
+		WithHeader("X-Tenant-ID", "override"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResponseTap(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chats/missing" {
+			w.WriteHeader(404)
+			w.Write([]byte(`{"error":"Not found"}`))
+			return
+		}
+		w.Write([]byte(`{"id":"chat-001","name":"Test","unknown_field":1}`))
+	}))
+	defer srv.Close()
+
+	type tapped struct {
+		method, path string
+		status       int
+		body         string
+	}
+	var calls []tapped
+	client := NewClient("key", WithBaseURL(srv.URL), WithResponseTap(func(method, path string, status int, body []byte) {
+		calls = append(calls, tapped{method, path, status, string(body)})
+	}))
+
+	chat, err := client.Chats.Get(context.Background(), "chat-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat.ID != "chat-001" {
+		t.Errorf("expected body to still decode, got %+v", chat)
+	}
+	if _, err := client.Chats.Get(context.Background(), "missing"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 tap calls, got %d", len(calls))
+	}
+	if calls[0].method != "GET" || calls[0].path != "/chats/chat-001" || calls[0].status != 200 || !strings.Contains(calls[0].body, "unknown_field") {
+		t.Errorf("unexpected first tap: %+v", calls[0])
+	}
+	if calls[1].status != 404 || calls[1].body != `{"error":"Not found"}` {
+		t.Errorf("unexpected second tap: %+v", calls[1])
+	}
+}
+
+type recordingLogger struct {
+	requests  []string
+	responses []int
+	errs      []error
+}
+
+func (l *recordingLogger) LogRequest(ctx context.Context, method, path string) {
+	l.requests = append(l.requests, method+" "+path)
+}
+
+func (l *recordingLogger) LogResponse(ctx context.Context, method, path string, status int, duration time.Duration, err error) {
+	l.responses = append(l.responses, status)
+	l.errs = append(l.errs, err)
+}
+
+func TestLogger(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chats/missing" {
+			w.WriteHeader(404)
+			return
+		}
+		json.NewEncoder(w).Encode(Chat{ID: "chat-001"})
+	}))
+	defer srv.Close()
+
+	logger := &recordingLogger{}
+	client := NewClient("key", WithBaseURL(srv.URL), WithLogger(logger))
+	client.Chats.Get(context.Background(), "chat-001")
+	client.Chats.Get(context.Background(), "missing")
+
+	if len(logger.requests) != 2 || logger.requests[0] != "GET /chats/chat-001" {
+		t.Errorf("unexpected requests: %v", logger.requests)
+	}
+	if len(logger.responses) != 2 || logger.responses[0] != 200 || logger.responses[1] != 404 {
+		t.Errorf("unexpected statuses: %v", logger.responses)
+	}
+	if logger.errs[0] != nil || logger.errs[1] == nil {
+		t.Errorf("unexpected errors: %v", logger.errs)
+	}
+}
+
+func TestRequestCompression(t *testing.T) {
+	var encodings []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		var reader io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader = zr
+		}
+		var body RunParams
+		json.NewDecoder(reader).Decode(&body)
+		json.NewEncoder(w).Encode(RunResponse{WorkflowRequestID: body.Query})
+	}))
+	defer srv.Close()
+
+	client := NewClient("key", WithBaseURL(srv.URL), WithRequestCompression())
+
+	large := strings.Repeat("x", 2048)
+	resp, err := client.Workflows.Run(context.Background(), RunParams{Query: large})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.WorkflowRequestID != large {
+		t.Error("expected server to decode compressed body")
+	}
+	if _, err := client.Workflows.Run(context.Background(), RunParams{Query: "small"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Chats.Delete(context.Background(), "chat-001"); err != nil {
+		t.Fatal(err)
+	}
+	if len(encodings) != 3 || encodings[0] != "gzip" || encodings[1] != "" || encodings[2] != "" {
+		t.Errorf("expected only the large body to be compressed, got %q", encodings)
+	}
+}
+
+func TestGzipResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		json.NewEncoder(zw).Encode(Chat{ID: "chat-001", Name: "Zipped"})
+		zw.Close()
+	}))
+	defer srv.Close()
+
+	// Setting Accept-Encoding ourselves disables the transport's transparent
+	// decompression, so the SDK has to handle the gzip body.
+	client := NewClient("key", WithBaseURL(srv.URL), WithDefaultHeaders(map[string]string{"Accept-Encoding": "gzip"}))
+	chat, err := client.Chats.Get(context.Background(), "chat-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat.Name != "Zipped" {
+		t.Errorf("expected Zipped, got %s", chat.Name)
+	}
+}
@@ -0,0 +1,148 @@
+package splox
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// VersionDiff lists the nodes and edges that differ between two workflow
+// versions, matched by ID. Each slice is sorted by ID.
+type VersionDiff struct {
+	FromVersion int
+	ToVersion   int
+
+	AddedNodes    []Node
+	RemovedNodes  []Node
+	ModifiedNodes []NodeChange
+
+	AddedEdges    []Edge
+	RemovedEdges  []Edge
+	ModifiedEdges []EdgeChange
+}
+
+// NodeChange is a node present in both versions with different content.
+type NodeChange struct {
+	ID     string
+	Before Node
+	After  Node
+}
+
+// EdgeChange is an edge present in both versions with different content.
+type EdgeChange struct {
+	ID     string
+	Before Edge
+	After  Edge
+}
+
+// IsEmpty reports whether the two versions have identical nodes and edges.
+func (d *VersionDiff) IsEmpty() bool {
+	return len(d.AddedNodes) == 0 && len(d.RemovedNodes) == 0 && len(d.ModifiedNodes) == 0 &&
+		len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0 && len(d.ModifiedEdges) == 0
+}
+
+// DiffVersions compares two versions of a workflow, identified by version
+// number. It is computed client-side from [WorkflowService.GetVersion].
+// Timestamps and version IDs are ignored when deciding whether a node or
+// edge was modified.
+func (s *WorkflowService) DiffVersions(ctx context.Context, workflowID string, fromVersion, toVersion int) (*VersionDiff, error) {
+	versions, err := s.ListVersions(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	fromID, toID := "", ""
+	for _, v := range versions.Versions {
+		switch v.VersionNumber {
+		case fromVersion:
+			fromID = v.ID
+		case toVersion:
+			toID = v.ID
+		}
+	}
+	if fromID == "" {
+		return nil, &ValidationError{Field: "fromVersion", Message: fmt.Sprintf("workflow has no version %d", fromVersion)}
+	}
+	if toID == "" && fromVersion == toVersion {
+		toID = fromID
+	}
+	if toID == "" {
+		return nil, &ValidationError{Field: "toVersion", Message: fmt.Sprintf("workflow has no version %d", toVersion)}
+	}
+
+	from, err := s.GetVersion(ctx, fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.GetVersion(ctx, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	d := diffWorkflows(from, to)
+	d.FromVersion = fromVersion
+	d.ToVersion = toVersion
+	return d, nil
+}
+
+// diffWorkflows compares the nodes and edges of two workflow versions.
+func diffWorkflows(from, to *WorkflowFullResponse) *VersionDiff {
+	d := &VersionDiff{}
+
+	nodeID := func(n Node) string { return n.ID }
+	normNode := func(n Node) Node {
+		n.WorkflowVersionID, n.CreatedAt, n.UpdatedAt = "", "", ""
+		return n
+	}
+	var changed [][2]Node
+	d.AddedNodes, d.RemovedNodes, changed = diffByID(from.Nodes, to.Nodes, nodeID, normNode)
+	for _, c := range changed {
+		d.ModifiedNodes = append(d.ModifiedNodes, NodeChange{ID: c[0].ID, Before: c[0], After: c[1]})
+	}
+
+	edgeID := func(e Edge) string { return e.ID }
+	normEdge := func(e Edge) Edge {
+		e.WorkflowVersionID, e.CreatedAt, e.UpdatedAt = "", "", ""
+		return e
+	}
+	var changedEdges [][2]Edge
+	d.AddedEdges, d.RemovedEdges, changedEdges = diffByID(from.Edges, to.Edges, edgeID, normEdge)
+	for _, c := range changedEdges {
+		d.ModifiedEdges = append(d.ModifiedEdges, EdgeChange{ID: c[0].ID, Before: c[0], After: c[1]})
+	}
+
+	return d
+}
+
+// diffByID matches items by ID and returns those only in to (added), only in
+// from (removed), and in both but unequal after normalization (changed, as
+// before/after pairs). Results are sorted by ID.
+func diffByID[T any](from, to []T, id func(T) string, normalize func(T) T) (added, removed []T, changed [][2]T) {
+	before := make(map[string]T, len(from))
+	for _, item := range from {
+		before[id(item)] = item
+	}
+	after := make(map[string]T, len(to))
+	for _, item := range to {
+		after[id(item)] = item
+	}
+
+	for _, item := range to {
+		old, ok := before[id(item)]
+		if !ok {
+			added = append(added, item)
+		} else if !reflect.DeepEqual(normalize(old), normalize(item)) {
+			changed = append(changed, [2]T{old, item})
+		}
+	}
+	for _, item := range from {
+		if _, ok := after[id(item)]; !ok {
+			removed = append(removed, item)
+		}
+	}
+
+	sort.Slice(added, func(i, j int) bool { return id(added[i]) < id(added[j]) })
+	sort.Slice(removed, func(i, j int) bool { return id(removed[i]) < id(removed[j]) })
+	sort.Slice(changed, func(i, j int) bool { return id(changed[i][0]) < id(changed[j][0]) })
+	return added, removed, changed
+}
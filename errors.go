@@ -1,10 +1,16 @@
 package splox
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 // APIError is returned when the API responds with a non-2xx status code.
@@ -18,6 +24,20 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("splox: API error %d: %s", e.StatusCode, e.Message)
 }
 
+// statusCode is promoted to every typed error that embeds APIError, such as
+// [NotFoundError], so [statusOf] can match them all.
+func (e *APIError) statusCode() int { return e.StatusCode }
+
+// statusOf returns the HTTP status of the API response behind err, or 0 if
+// err did not come from an API response.
+func statusOf(err error) int {
+	var withStatus interface{ statusCode() int }
+	if errors.As(err, &withStatus) {
+		return withStatus.statusCode()
+	}
+	return 0
+}
+
 // AuthError is returned on 401 Unauthorized.
 type AuthError struct{ APIError }
 
@@ -27,6 +47,10 @@ type ForbiddenError struct{ APIError }
 // NotFoundError is returned on 404 Not Found.
 type NotFoundError struct{ APIError }
 
+// ConflictError is returned on 409 Conflict, when the request is valid but
+// the resource is not in a state that allows it.
+type ConflictError struct{ APIError }
+
 // GoneError is returned on 410 Gone.
 type GoneError struct{ APIError }
 
@@ -36,6 +60,10 @@ type RateLimitError struct {
 	RetryAfter string // raw Retry-After header value
 }
 
+// EstimateUnavailableError is returned by [WorkflowService.EstimateCost]
+// when the server cannot estimate the cost of a workflow.
+type EstimateUnavailableError struct{ APIError }
+
 // ConnectionError is returned when the HTTP request fails at the transport level.
 type ConnectionError struct {
 	Err error
@@ -56,6 +84,81 @@ func (e *TimeoutError) Error() string {
 	return fmt.Sprintf("splox: timeout: %s", e.Message)
 }
 
+// ValidationError is returned when request parameters fail client-side
+// validation, before any request is sent.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("splox: validation error: %s", e.Message)
+	}
+	return fmt.Sprintf("splox: validation error: %s: %s", e.Field, e.Message)
+}
+
+// SignatureError is returned when a webhook signature fails verification.
+type SignatureError struct {
+	Message string
+}
+
+func (e *SignatureError) Error() string {
+	return fmt.Sprintf("splox: invalid webhook signature: %s", e.Message)
+}
+
+// TokenError is returned when a connection token fails verification in
+// [ParseConnectionToken].
+type TokenError struct {
+	Message string
+}
+
+func (e *TokenError) Error() string {
+	return fmt.Sprintf("splox: invalid connection token: %s", e.Message)
+}
+
+// ExecutionError is returned when a workflow's event stream reports an
+// error event while waiting for the run to finish, or, with
+// [WithErrorOnFailure], when the run ends with status "failed". In the latter
+// case NodeLabel and Output describe the first failed node, if any.
+type ExecutionError struct {
+	WorkflowRequestID string
+	Message           string
+	NodeLabel         string
+	Output            map[string]any
+}
+
+func (e *ExecutionError) Error() string {
+	if e.NodeLabel != "" {
+		return fmt.Sprintf("splox: execution error in request %s at node %q: %s", e.WorkflowRequestID, e.NodeLabel, e.Message)
+	}
+	return fmt.Sprintf("splox: execution error in request %s: %s", e.WorkflowRequestID, e.Message)
+}
+
+// ResponseTooLargeError is returned when a response body exceeds the limit
+// set by [WithMaxResponseBytes].
+type ResponseTooLargeError struct {
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("splox: response body exceeds %d bytes", e.Limit)
+}
+
+// CircuitOpenError is returned without sending the request while the
+// client's circuit breaker is open; see [WithCircuitBreaker]. RetryAfter is
+// the time left in the cooldown, or zero while a probe request is in flight.
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("splox: circuit breaker open, retry after %s", e.RetryAfter.Round(time.Millisecond))
+	}
+	return "splox: circuit breaker open, probe in flight"
+}
+
 // StreamError is returned when SSE stream parsing fails.
 type StreamError struct {
 	Err error
@@ -67,14 +170,100 @@ func (e *StreamError) Error() string {
 
 func (e *StreamError) Unwrap() error { return e.Err }
 
+// IsRetryable reports whether err is likely transient, so the same call
+// (including [WorkflowService.Listen] and other streams) may succeed if
+// retried after a backoff. Rate limits, 408, 5xx responses, connection
+// failures, dropped streams, and an open circuit breaker are retryable.
+// Authentication and permission errors (401/403), other 4xx responses,
+// validation errors, and cancellation of the caller's context are not:
+// retrying a 401 or 403 will keep failing.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var rateLimit *RateLimitError
+	if errors.As(err, &rateLimit) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusRequestTimeout || apiErr.StatusCode >= 500
+	}
+
+	var connErr *ConnectionError
+	var streamErr *StreamError
+	var circuitErr *CircuitOpenError
+	return errors.As(err, &connErr) || errors.As(err, &streamErr) || errors.As(err, &circuitErr)
+}
+
+// redactedText replaces sensitive values in error output.
+const redactedText = "[REDACTED]"
+
+// maxErrorMessageLen caps the length of APIError.Message so a huge error
+// body doesn't flood logs. ResponseBody is not truncated.
+const maxErrorMessageLen = 1024
+
+// sensitiveHeaders are request headers whose values must never appear in
+// error output.
+var sensitiveHeaders = []string{"Authorization", "X-Webhook-Secret"}
+
+// redact masks the values of req's sensitive headers in s, including a
+// bearer token on its own.
+func redact(s string, req *http.Request) string {
+	if req == nil {
+		return s
+	}
+	for _, h := range sensitiveHeaders {
+		v := req.Header.Get(h)
+		if v == "" {
+			continue
+		}
+		s = strings.ReplaceAll(s, v, redactedText)
+		if token, ok := strings.CutPrefix(v, "Bearer "); ok && token != "" {
+			s = strings.ReplaceAll(s, token, redactedText)
+		}
+	}
+	return s
+}
+
+// truncate shortens s to at most n bytes, marking the cut. The cut is moved
+// back to a rune boundary so a multi-byte character is never split.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "...(truncated)"
+}
+
+// redactURLError drops the query string from a transport error's URL, since
+// query parameters may carry sensitive values.
+func redactURLError(err error) error {
+	ue, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+	i := strings.IndexByte(ue.URL, '?')
+	if i < 0 {
+		return err
+	}
+	c := *ue
+	c.URL = ue.URL[:i] + "?" + redactedText
+	return &c
+}
+
 // checkStatus inspects an HTTP response and returns a typed error for non-2xx.
+// Sensitive request headers echoed in the body are redacted.
 func checkStatus(resp *http.Response) error {
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
 		return nil
 	}
 
 	body, _ := io.ReadAll(resp.Body)
-	bodyStr := string(body)
+	bodyStr := redact(string(body), resp.Request)
 
 	base := APIError{
 		StatusCode:   resp.StatusCode,
@@ -87,8 +276,9 @@ func checkStatus(resp *http.Response) error {
 		Error string `json:"error"`
 	}
 	if json.Unmarshal(body, &parsed) == nil && parsed.Error != "" {
-		base.Message = parsed.Error
+		base.Message = redact(parsed.Error, resp.Request)
 	}
+	base.Message = truncate(base.Message, maxErrorMessageLen)
 
 	switch resp.StatusCode {
 	case 401:
@@ -97,6 +287,8 @@ func checkStatus(resp *http.Response) error {
 		return &ForbiddenError{APIError: base}
 	case 404:
 		return &NotFoundError{APIError: base}
+	case 409:
+		return &ConflictError{APIError: base}
 	case 410:
 		return &GoneError{APIError: base}
 	case 429:
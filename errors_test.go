@@ -1,10 +1,13 @@
 package splox
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestCheckStatus401(t *testing.T) {
@@ -64,6 +67,25 @@ func TestCheckStatus404(t *testing.T) {
 	}
 }
 
+func TestCheckStatus409(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(409)
+		w.Write([]byte(`{"error":"Child execution has not failed"}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient("key", WithBaseURL(srv.URL))
+	_, err := client.Workflows.RetryChild(t.Context(), "req-001", 3)
+
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("expected ConflictError, got %T", err)
+	}
+	if IsRetryable(err) {
+		t.Error("expected ConflictError not to be retryable")
+	}
+}
+
 func TestCheckStatus410(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(410)
@@ -128,3 +150,131 @@ func TestConnectionError(t *testing.T) {
 		t.Fatalf("expected ConnectionError, got %T: %v", err, err)
 	}
 }
+
+func TestErrorsRedactSecrets(t *testing.T) {
+	const token = "sk-live-0123456789abcdef"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+		w.Write([]byte(`{"error":"bad request, got headers ` + r.Header.Get("Authorization") + ` / ` + r.Header.Get("X-Webhook-Secret") + `"}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(token, WithBaseURL(srv.URL))
+	_, err := client.Chats.Get(t.Context(), "chat-001")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if strings.Contains(err.Error(), token) {
+		t.Errorf("bearer token leaked into error: %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || strings.Contains(apiErr.ResponseBody, token) {
+		t.Errorf("bearer token leaked into response body: %+v", apiErr)
+	}
+
+	_, err = client.Events.Send(t.Context(), SendEventParams{WebhookID: "wh-001", Secret: "whsec-topsecret"})
+	if err == nil || strings.Contains(err.Error(), "whsec-topsecret") {
+		t.Errorf("webhook secret leaked into error: %v", err)
+	}
+}
+
+func TestErrorsTruncateLongMessage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+		w.Write([]byte(strings.Repeat("x", 10_000)))
+	}))
+	defer srv.Close()
+
+	client := NewClient("key", WithBaseURL(srv.URL))
+	_, err := client.Chats.Get(t.Context(), "chat-001")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+	if len(apiErr.Message) > maxErrorMessageLen+len("...(truncated)") {
+		t.Errorf("message not truncated: %d bytes", len(apiErr.Message))
+	}
+	if len(apiErr.ResponseBody) != 10_000 {
+		t.Errorf("expected full response body, got %d bytes", len(apiErr.ResponseBody))
+	}
+}
+
+func TestTruncateKeepsValidUTF8(t *testing.T) {
+	s := strings.Repeat("é", 10) // 2 bytes per rune
+	got := truncate(s, 5)
+	if !utf8.ValidString(got) {
+		t.Errorf("expected valid UTF-8, got %q", got)
+	}
+	if got != "éé...(truncated)" {
+		t.Errorf("expected cut at the previous rune boundary, got %q", got)
+	}
+}
+
+func TestConnectionErrorRedactsQuery(t *testing.T) {
+	client := NewClient("key", WithBaseURL("http://127.0.0.1:1"))
+	_, err := client.Workflows.List(t.Context(), &ListParams{Search: "secret-search-term"})
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Fatalf("expected ConnectionError, got %v", err)
+	}
+	if strings.Contains(err.Error(), "secret-search-term") {
+		t.Errorf("query string leaked into error: %v", err)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"auth", &AuthError{APIError{StatusCode: 401}}, false},
+		{"forbidden", &ForbiddenError{APIError{StatusCode: 403}}, false},
+		{"not found", &NotFoundError{APIError{StatusCode: 404}}, false},
+		{"bad request", &APIError{StatusCode: 400}, false},
+		{"validation", &ValidationError{Message: "x"}, false},
+		{"rate limit", &RateLimitError{APIError: APIError{StatusCode: 429}}, true},
+		{"unavailable", &APIError{StatusCode: 503}, true},
+		{"request timeout", &APIError{StatusCode: 408}, true},
+		{"connection", &ConnectionError{Err: errors.New("dial tcp: refused")}, true},
+		{"stream", &StreamError{Err: errors.New("unexpected EOF")}, true},
+		{"canceled", &StreamError{Err: context.Canceled}, false},
+	}
+	for _, tc := range cases {
+		if got := IsRetryable(tc.err); got != tc.want {
+			t.Errorf("%s: IsRetryable = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestStreamSSEStatusErrors(t *testing.T) {
+	status := 401
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"error":"nope"}`))
+	}))
+	defer srv.Close()
+	client := NewClient("key", WithBaseURL(srv.URL))
+
+	_, err := client.Workflows.Listen(t.Context(), "req-1")
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("expected AuthError from Listen, got %T: %v", err, err)
+	}
+	if IsRetryable(err) {
+		t.Error("401 from Listen should not be retryable")
+	}
+
+	status = 503
+	_, err = client.Workflows.Listen(t.Context(), "req-1")
+	if !IsRetryable(err) {
+		t.Errorf("503 from Listen should be retryable, got %v", err)
+	}
+
+	down := NewClient("key", WithBaseURL("http://127.0.0.1:1"))
+	_, err = down.Workflows.Listen(t.Context(), "req-1")
+	if !IsRetryable(err) {
+		t.Errorf("connection failure should be retryable, got %v", err)
+	}
+}
@@ -1,6 +1,18 @@
 package splox
 
-import "context"
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
 
 // EventService provides methods for the Events / Webhooks API.
 type EventService struct {
@@ -12,30 +24,209 @@ type SendEventParams struct {
 	WebhookID string
 	Payload   map[string]any
 	Secret    string // optional, sent as X-Webhook-Secret header
+
+	// DryRun asks the server to validate the payload without starting a
+	// run; it confirms this by setting EventResponse.DryRun.
+	DryRun bool
 }
 
 // Send triggers a workflow via webhook. No API key is required.
 func (s *EventService) Send(ctx context.Context, params SendEventParams) (*EventResponse, error) {
-	payload := params.Payload
+	var payload any = params.Payload
+	if params.Payload == nil {
+		payload = map[string]any{}
+	}
+	return s.send(ctx, params.WebhookID, payload, eventConfig{secret: params.Secret, dryRun: params.DryRun})
+}
+
+// eventConfig holds settings for [EventService.SendTyped].
+type eventConfig struct {
+	secret string
+	dryRun bool
+}
+
+// EventOption configures [EventService.SendTyped].
+type EventOption func(*eventConfig)
+
+// WithWebhookSecret sends secret in the X-Webhook-Secret header.
+func WithWebhookSecret(secret string) EventOption {
+	return func(c *eventConfig) { c.secret = secret }
+}
+
+// WithDryRun validates the event without starting a run, like
+// SendEventParams.DryRun.
+func WithDryRun() EventOption {
+	return func(c *eventConfig) { c.dryRun = true }
+}
+
+// SendTyped is like [EventService.Send] but accepts any JSON-marshalable
+// payload, such as a struct with json tags. A nil payload is sent as {}.
+func (s *EventService) SendTyped(ctx context.Context, webhookID string, payload any, opts ...EventOption) (*EventResponse, error) {
+	var cfg eventConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 	if payload == nil {
 		payload = map[string]any{}
 	}
+	return s.send(ctx, webhookID, payload, cfg)
+}
 
-	if params.Secret != "" {
-		fullURL := s.client.baseURL + "/events/" + params.WebhookID
-		var resp EventResponse
-		err := s.client.doWithHeaders(ctx, "POST", fullURL, payload, &resp, map[string]string{
-			"X-Webhook-Secret": params.Secret,
-		})
-		if err != nil {
-			return nil, err
-		}
-		return &resp, nil
+// send posts payload to the webhook.
+func (s *EventService) send(ctx context.Context, webhookID string, payload any, cfg eventConfig) (*EventResponse, error) {
+	path := "/events/" + webhookID
+	if cfg.dryRun {
+		path = addParams(path, url.Values{"dry_run": {"true"}})
+	}
+
+	var headers map[string]string
+	if cfg.secret != "" {
+		headers = map[string]string{"X-Webhook-Secret": cfg.secret}
 	}
 
 	var resp EventResponse
-	if err := s.client.do(ctx, "POST", "/events/"+params.WebhookID, payload, &resp); err != nil {
+	if err := s.client.doWithHeaders(ctx, "POST", s.client.url(path), payload, &resp, headers, nil); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
+
+// DefaultBatchConcurrency is the number of requests a batch method sends in
+// parallel unless overridden with [WithConcurrency].
+const DefaultBatchConcurrency = 8
+
+// batchConfig holds settings for batch methods; see [BatchOption].
+type batchConfig struct {
+	concurrency int
+}
+
+// BatchOption configures [EventService.SendBatch], [WorkflowService.StopAll],
+// [WorkflowService.GetExecutionTrees] and [WorkflowService.SetEnvSecrets].
+type BatchOption func(*batchConfig)
+
+// WithConcurrency sets the maximum number of in-flight requests for a batch.
+func WithConcurrency(n int) BatchOption {
+	return func(c *batchConfig) { c.concurrency = n }
+}
+
+// batchConcurrency returns the concurrency configured by opts.
+func batchConcurrency(opts []BatchOption) int {
+	cfg := batchConfig{concurrency: DefaultBatchConcurrency}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg.concurrency
+}
+
+// runBounded calls fn for each index in [0, n) with at most concurrency calls
+// in flight, and joins the errors they return in index order. Once ctx is
+// done no further calls are started and ctx's error is included.
+func runBounded(ctx context.Context, n, concurrency int, fn func(i int) error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	errs := make([]error, n)
+	var stopErr error
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		if ctx.Err() == nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			stopErr = fmt.Errorf("splox: batch stopped after starting %d of %d items: %w", i, n, err)
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+	return errors.Join(append(errs, stopErr)...)
+}
+
+// SendBatch sends each payload to the same webhook using a bounded pool of
+// workers. Results are returned in input order. A failed item leaves a zero
+// EventResponse at its index and does not stop the rest of the batch; all
+// failures are joined into the returned error.
+func (s *EventService) SendBatch(ctx context.Context, webhookID string, payloads []map[string]any, opts ...BatchOption) ([]EventResponse, error) {
+	results := make([]EventResponse, len(payloads))
+	err := runBounded(ctx, len(payloads), batchConcurrency(opts), func(i int) error {
+		resp, err := s.Send(ctx, SendEventParams{WebhookID: webhookID, Payload: payloads[i]})
+		if err != nil {
+			return fmt.Errorf("splox: batch item %d: %w", i, err)
+		}
+		results[i] = *resp
+		return nil
+	})
+	return results, err
+}
+
+// DefaultWebhookTolerance is the maximum age of a webhook signature timestamp
+// accepted by [VerifyWebhookSignature].
+const DefaultWebhookTolerance = 5 * time.Minute
+
+// VerifyWebhookSignature checks that payload was signed by Splox with secret.
+//
+// signatureHeader has the form "t=<unix timestamp>,v1=<hex signature>", where
+// the signature is the HMAC-SHA256 of "<timestamp>.<payload>". Signatures older
+// than [DefaultWebhookTolerance] are rejected to prevent replays.
+func VerifyWebhookSignature(payload []byte, signatureHeader, secret string) error {
+	return VerifyWebhookSignatureWithTolerance(payload, signatureHeader, secret, DefaultWebhookTolerance)
+}
+
+// VerifyWebhookSignatureWithTolerance is like [VerifyWebhookSignature] but
+// uses a custom replay tolerance. A tolerance of zero disables the timestamp check.
+func VerifyWebhookSignatureWithTolerance(payload []byte, signatureHeader, secret string, tolerance time.Duration) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(signatureHeader, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "t":
+			timestamp = v
+		case "v1":
+			signatures = append(signatures, v)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return &SignatureError{Message: "malformed signature header"}
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return &SignatureError{Message: fmt.Sprintf("invalid timestamp %q", timestamp)}
+	}
+	if tolerance > 0 {
+		age := time.Since(time.Unix(ts, 0))
+		if age > tolerance || age < -tolerance {
+			return &SignatureError{Message: "timestamp outside tolerance window"}
+		}
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+
+	for _, sig := range signatures {
+		got, err := hex.DecodeString(sig)
+		if err != nil {
+			continue
+		}
+		if hmac.Equal(got, expected) {
+			return nil
+		}
+	}
+	return &SignatureError{Message: "signature mismatch"}
+}
@@ -0,0 +1,226 @@
+package splox
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Keys under ExecutionNode.OutputData where the server reports per-node cost
+// and token usage. Keep these in one place so server-side renames only need a
+// single update.
+const (
+	outputCostKey         = "cost"
+	outputUsageKey        = "usage"
+	outputInputTokensKey  = "input_tokens"
+	outputOutputTokensKey = "output_tokens"
+	outputErrorKey        = "error"
+)
+
+// Cost returns the USD cost reported for this node execution, if any.
+func (n ExecutionNode) Cost() (float64, bool) {
+	return toFloat(n.OutputData[outputCostKey])
+}
+
+// Tokens returns the input and output token counts reported for this node
+// execution, if any.
+func (n ExecutionNode) Tokens() (in, out int64, ok bool) {
+	usage, isMap := n.OutputData[outputUsageKey].(map[string]any)
+	if !isMap {
+		return 0, 0, false
+	}
+	inF, inOK := toFloat(usage[outputInputTokensKey])
+	outF, outOK := toFloat(usage[outputOutputTokensKey])
+	if !inOK && !outOK {
+		return 0, 0, false
+	}
+	return int64(inF), int64(outF), true
+}
+
+// Duration returns the time between CreatedAt and CompletedAt, falling back
+// to FailedAt for failed nodes. ok is false if either timestamp is missing or
+// unparseable.
+func (n ExecutionNode) Duration() (d time.Duration, ok bool) {
+	end := n.CompletedAt
+	if end == "" {
+		end = n.FailedAt
+	}
+	return elapsed(n.CreatedAt, end)
+}
+
+// Duration returns the time between CreatedAt and CompletedAt. ok is false
+// if either timestamp is missing or unparseable.
+func (r WorkflowRequest) Duration() (d time.Duration, ok bool) {
+	return elapsed(r.CreatedAt, r.CompletedAt)
+}
+
+// TotalDuration returns the time between the tree's CreatedAt and
+// CompletedAt. ok is false if either timestamp is missing or unparseable.
+func (t ExecutionTree) TotalDuration() (d time.Duration, ok bool) {
+	return elapsed(t.CreatedAt, t.CompletedAt)
+}
+
+// elapsed parses two RFC 3339 timestamps and returns end minus start.
+func elapsed(start, end string) (time.Duration, bool) {
+	if start == "" || end == "" {
+		return 0, false
+	}
+	s, err := time.Parse(time.RFC3339Nano, start)
+	if err != nil {
+		return 0, false
+	}
+	e, err := time.Parse(time.RFC3339Nano, end)
+	if err != nil {
+		return 0, false
+	}
+	return e.Sub(s), true
+}
+
+// Usage sums cost and token counts across every node in the tree, including
+// nodes of child executions.
+func (t ExecutionTree) Usage() (cost float64, usage TokenUsage) {
+	walkNodes(t.Nodes, func(n ExecutionNode) {
+		if c, ok := n.Cost(); ok {
+			cost += c
+		}
+		if in, out, ok := n.Tokens(); ok {
+			usage.InputTokens += in
+			usage.OutputTokens += out
+		}
+	})
+	usage.TotalTokens = usage.InputTokens + usage.OutputTokens
+	return cost, usage
+}
+
+// requestCost builds a per-node cost breakdown of the tree. Nodes that
+// report neither cost nor tokens are left out.
+func (t ExecutionTree) requestCost() *RequestCost {
+	rc := &RequestCost{WorkflowRequestID: t.WorkflowRequestID}
+	walkNodes(t.Nodes, func(n ExecutionNode) {
+		c, costOK := n.Cost()
+		in, out, tokensOK := n.Tokens()
+		if !costOK && !tokensOK {
+			return
+		}
+		rc.Nodes = append(rc.Nodes, NodeCost{
+			NodeID:       n.NodeID,
+			NodeLabel:    n.NodeLabel,
+			NodeType:     n.NodeType,
+			CostUSD:      c,
+			InputTokens:  in,
+			OutputTokens: out,
+		})
+		rc.TotalCostUSD += c
+		rc.InputTokens += in
+		rc.OutputTokens += out
+	})
+	return rc
+}
+
+// failure returns an [*ExecutionError] for the first failed node of the tree,
+// depth-first, or a generic one if no node is marked as failed. The message
+// is the node's "error" output when present.
+func (t ExecutionTree) failure() *ExecutionError {
+	e := &ExecutionError{WorkflowRequestID: t.WorkflowRequestID, Message: "workflow failed"}
+	var found bool
+	walkNodes(t.Nodes, func(n ExecutionNode) {
+		if found || (Status(n.Status) != StatusFailed && n.FailedAt == "") {
+			return
+		}
+		found = true
+		e.NodeLabel = n.NodeLabel
+		e.Output = n.OutputData
+		e.Message = "node failed"
+		if msg, ok := n.OutputData[outputErrorKey].(string); ok && msg != "" {
+			e.Message = msg
+		}
+	})
+	return e
+}
+
+// FindNode returns the first node, depth-first and including nodes of child
+// executions, for which match returns true.
+func (t ExecutionTree) FindNode(match func(ExecutionNode) bool) (*ExecutionNode, bool) {
+	n := findNode(t.Nodes, match)
+	return n, n != nil
+}
+
+// FindByID returns the first node with the given execution node ID.
+func (t ExecutionTree) FindByID(id string) (*ExecutionNode, bool) {
+	return t.FindNode(func(n ExecutionNode) bool { return n.ID == id })
+}
+
+// FindByLabel returns the first node with the given label.
+func (t ExecutionTree) FindByLabel(label string) (*ExecutionNode, bool) {
+	return t.FindNode(func(n ExecutionNode) bool { return n.NodeLabel == label })
+}
+
+// findNode is the recursive search behind [ExecutionTree.FindNode].
+func findNode(nodes []ExecutionNode, match func(ExecutionNode) bool) *ExecutionNode {
+	for i := range nodes {
+		if match(nodes[i]) {
+			return &nodes[i]
+		}
+		for _, child := range nodes[i].ChildExecutions {
+			if n := findNode(child.Nodes, match); n != nil {
+				return n
+			}
+		}
+	}
+	return nil
+}
+
+// FlatNode is an [ExecutionNode] annotated with its position in the tree, as
+// returned by [ExecutionTree.Flatten].
+type FlatNode struct {
+	ExecutionNode
+	Depth           int    // 0 for top-level nodes, +1 per child execution
+	ParentRequestID string // workflow request the node ran in
+}
+
+// Flatten returns every node in the tree, depth-first and including nodes of
+// child executions, with each node directly followed by its children.
+func (t ExecutionTree) Flatten() []FlatNode {
+	var out []FlatNode
+	flattenNodes(t.Nodes, 0, t.WorkflowRequestID, &out)
+	return out
+}
+
+// flattenNodes appends nodes and their descendants to out.
+func flattenNodes(nodes []ExecutionNode, depth int, requestID string, out *[]FlatNode) {
+	for _, n := range nodes {
+		*out = append(*out, FlatNode{ExecutionNode: n, Depth: depth, ParentRequestID: requestID})
+		for _, child := range n.ChildExecutions {
+			flattenNodes(child.Nodes, depth+1, child.WorkflowRequestID, out)
+		}
+	}
+}
+
+// walkNodes calls fn for each node, depth-first, including nodes of child
+// executions.
+func walkNodes(nodes []ExecutionNode, fn func(ExecutionNode)) {
+	for _, n := range nodes {
+		fn(n)
+		for _, child := range n.ChildExecutions {
+			walkNodes(child.Nodes, fn)
+		}
+	}
+}
+
+// toFloat converts a decoded JSON number to float64.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,148 @@
+package splox
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExecutionNodeCostAndTokens(t *testing.T) {
+	n := ExecutionNode{
+		OutputData: map[string]any{
+			"cost":  0.25,
+			"usage": map[string]any{"input_tokens": float64(100), "output_tokens": float64(40)},
+		},
+	}
+
+	cost, ok := n.Cost()
+	if !ok || cost != 0.25 {
+		t.Errorf("expected cost 0.25, got %v (ok=%v)", cost, ok)
+	}
+	in, out, ok := n.Tokens()
+	if !ok || in != 100 || out != 40 {
+		t.Errorf("expected 100/40 tokens, got %d/%d (ok=%v)", in, out, ok)
+	}
+
+	if _, ok := (ExecutionNode{}).Cost(); ok {
+		t.Error("expected no cost on empty node")
+	}
+	if _, _, ok := (ExecutionNode{}).Tokens(); ok {
+		t.Error("expected no tokens on empty node")
+	}
+}
+
+func TestExecutionTreeUsage(t *testing.T) {
+	tree := ExecutionTree{
+		Nodes: []ExecutionNode{
+			{
+				OutputData: map[string]any{"cost": 0.5, "usage": map[string]any{"input_tokens": float64(10), "output_tokens": float64(5)}},
+				ChildExecutions: []ChildExecution{
+					{Nodes: []ExecutionNode{
+						{OutputData: map[string]any{"cost": 0.25, "usage": map[string]any{"input_tokens": float64(3), "output_tokens": float64(2)}}},
+					}},
+				},
+			},
+			{NodeLabel: "no usage"},
+		},
+	}
+
+	cost, usage := tree.Usage()
+	if cost != 0.75 {
+		t.Errorf("expected cost 0.75, got %v", cost)
+	}
+	if usage.InputTokens != 13 || usage.OutputTokens != 7 || usage.TotalTokens != 20 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestExecutionTreeFindNode(t *testing.T) {
+	tree := ExecutionTree{
+		Nodes: []ExecutionNode{
+			{
+				ID:        "en-1",
+				NodeLabel: "Router",
+				ChildExecutions: []ChildExecution{
+					{Nodes: []ExecutionNode{
+						{ID: "en-2", NodeLabel: "Agent", OutputData: map[string]any{"text": "child"}},
+					}},
+				},
+			},
+			{ID: "en-3", NodeLabel: "Agent", OutputData: map[string]any{"text": "top"}},
+		},
+	}
+
+	n, ok := tree.FindByLabel("Agent")
+	if !ok || n.ID != "en-2" {
+		t.Errorf("expected first match depth-first (en-2), got %+v", n)
+	}
+	if n, ok := tree.FindByID("en-3"); !ok || n.OutputData["text"] != "top" {
+		t.Errorf("expected en-3, got %+v", n)
+	}
+	if n, ok := tree.FindNode(func(n ExecutionNode) bool { return n.NodeLabel == "Missing" }); ok || n != nil {
+		t.Errorf("expected no match, got %+v", n)
+	}
+}
+
+func TestExecutionTreeFlatten(t *testing.T) {
+	tree := ExecutionTree{
+		WorkflowRequestID: "req-1",
+		Nodes: []ExecutionNode{
+			{
+				ID: "en-1",
+				ChildExecutions: []ChildExecution{
+					{WorkflowRequestID: "req-2", Nodes: []ExecutionNode{
+						{ID: "en-2", ChildExecutions: []ChildExecution{
+							{WorkflowRequestID: "req-3", Nodes: []ExecutionNode{{ID: "en-3"}}},
+						}},
+					}},
+				},
+			},
+			{ID: "en-4"},
+		},
+	}
+
+	want := []struct {
+		id, parent string
+		depth      int
+	}{
+		{"en-1", "req-1", 0},
+		{"en-2", "req-2", 1},
+		{"en-3", "req-3", 2},
+		{"en-4", "req-1", 0},
+	}
+	flat := tree.Flatten()
+	if len(flat) != len(want) {
+		t.Fatalf("expected %d nodes, got %d", len(want), len(flat))
+	}
+	for i, w := range want {
+		if flat[i].ID != w.id || flat[i].ParentRequestID != w.parent || flat[i].Depth != w.depth {
+			t.Errorf("node %d: expected %+v, got id=%s parent=%s depth=%d", i, w, flat[i].ID, flat[i].ParentRequestID, flat[i].Depth)
+		}
+	}
+}
+
+func TestDurations(t *testing.T) {
+	n := ExecutionNode{CreatedAt: "2025-01-01T00:00:00Z", CompletedAt: "2025-01-01T00:00:01.5Z"}
+	if d, ok := n.Duration(); !ok || d != 1500*time.Millisecond {
+		t.Errorf("expected 1.5s, got %s (ok=%v)", d, ok)
+	}
+	failed := ExecutionNode{CreatedAt: "2025-01-01T00:00:00Z", FailedAt: "2025-01-01T00:00:02Z"}
+	if d, ok := failed.Duration(); !ok || d != 2*time.Second {
+		t.Errorf("expected FailedAt fallback of 2s, got %s (ok=%v)", d, ok)
+	}
+	if _, ok := (ExecutionNode{CreatedAt: "2025-01-01T00:00:00Z"}).Duration(); ok {
+		t.Error("expected ok=false for a running node")
+	}
+
+	r := WorkflowRequest{CreatedAt: "2025-01-01T00:00:00+02:00", CompletedAt: "2025-01-01T00:01:00+02:00"}
+	if d, ok := r.Duration(); !ok || d != time.Minute {
+		t.Errorf("expected 1m, got %s (ok=%v)", d, ok)
+	}
+	if _, ok := (WorkflowRequest{CreatedAt: "yesterday", CompletedAt: "today"}).Duration(); ok {
+		t.Error("expected ok=false for unparseable timestamps")
+	}
+
+	tree := ExecutionTree{CreatedAt: "2025-01-01T00:00:00Z", CompletedAt: "2025-01-01T01:00:00Z"}
+	if d, ok := tree.TotalDuration(); !ok || d != time.Hour {
+		t.Errorf("expected 1h, got %s (ok=%v)", d, ok)
+	}
+}
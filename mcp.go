@@ -1,20 +1,27 @@
 package splox
 
 import (
+	"bytes"
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
 // MCPService provides methods for MCP catalog browsing and connection management.
 type MCPService struct {
 	client *Client
+
+	mu    sync.Mutex
+	tools map[string]map[string]MCPTool // mcpServerID -> tool slug -> tool
 }
 
 // --------------------------------------------------------------------------
@@ -23,10 +30,12 @@ type MCPService struct {
 
 // CatalogParams are optional filters for [MCPService.ListCatalog].
 type CatalogParams struct {
-	Page     int
-	PerPage  int
-	Search   string
-	Featured bool
+	Page      int
+	PerPage   int
+	Search    string
+	Featured  bool
+	Category  string // matches MCPCatalogItem.Category
+	Transport string // matches MCPCatalogItem.TransportType, e.g. "http"
 }
 
 // ListCatalog returns a paginated list of MCP servers from the catalog.
@@ -45,6 +54,12 @@ func (s *MCPService) ListCatalog(ctx context.Context, params *CatalogParams) (*M
 		if params.Featured {
 			v.Set("featured", "true")
 		}
+		if params.Category != "" {
+			v.Set("category", params.Category)
+		}
+		if params.Transport != "" {
+			v.Set("transport_type", params.Transport)
+		}
 	}
 
 	var resp MCPCatalogListResponse
@@ -54,6 +69,43 @@ func (s *MCPService) ListCatalog(ctx context.Context, params *CatalogParams) (*M
 	return &resp, nil
 }
 
+// CatalogIter iterates over every MCP catalog item across pages.
+// Call [CatalogIter.Next] in a loop and check [CatalogIter.Err] afterwards.
+type CatalogIter struct {
+	p *pager[MCPCatalogItem]
+}
+
+// Next advances to the next catalog item. It returns false when the catalog
+// is exhausted, the context is done, or a request fails.
+func (it *CatalogIter) Next() bool { return it.p.next() }
+
+// Item returns the current catalog item. Only valid after [CatalogIter.Next] returns true.
+func (it *CatalogIter) Item() MCPCatalogItem { return it.p.cur }
+
+// Err returns any error encountered during iteration.
+func (it *CatalogIter) Err() error { return it.p.err }
+
+// AllCatalog returns an iterator over the whole MCP catalog, fetching pages
+// on demand. Filters apply to every page; Page sets the starting page.
+func (s *MCPService) AllCatalog(ctx context.Context, params *CatalogParams) *CatalogIter {
+	var p CatalogParams
+	if params != nil {
+		p = *params
+	}
+	if p.Page < 1 {
+		p.Page = 1
+	}
+
+	return &CatalogIter{p: newPager(ctx, func(ctx context.Context) ([]MCPCatalogItem, bool, error) {
+		resp, err := s.ListCatalog(ctx, &p)
+		if err != nil {
+			return nil, false, err
+		}
+		p.Page++
+		return resp.MCPServers, resp.HasNextPage(), nil
+	})}
+}
+
 // GetCatalogItem returns a single MCP server from the catalog by ID.
 func (s *MCPService) GetCatalogItem(ctx context.Context, id string) (*MCPCatalogItem, error) {
 	var resp MCPCatalogResponse
@@ -97,11 +149,110 @@ func (s *MCPService) ListConnections(ctx context.Context, params *ConnectionPara
 	return &resp, nil
 }
 
+// GetConnection returns a single MCP connection by ID, e.g. to poll its
+// Status after generating a connection link.
+func (s *MCPService) GetConnection(ctx context.Context, id string) (*MCPConnection, error) {
+	var resp MCPConnection
+	if err := s.client.do(ctx, "GET", "/mcp-connections/"+id, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateConnectionParams are the parameters for [MCPService.CreateConnection].
+type CreateConnectionParams struct {
+	MCPServerID string         `json:"mcp_server_id"`
+	EndUserID   string         `json:"end_user_id"`
+	Credentials map[string]any `json:"credentials,omitempty"`
+}
+
+// CreateConnection creates an end-user MCP connection with credentials the
+// caller already holds, bypassing the connection link flow.
+func (s *MCPService) CreateConnection(ctx context.Context, params CreateConnectionParams) (*MCPConnection, error) {
+	var resp MCPConnection
+	if err := s.client.do(ctx, "POST", "/mcp-connections", params, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // DeleteConnection deletes an end-user MCP connection by ID.
 func (s *MCPService) DeleteConnection(ctx context.Context, id string) error {
 	return s.client.do(ctx, "DELETE", "/mcp-connections/"+id, nil, nil)
 }
 
+// DeleteConnectionsForEndUser deletes every connection belonging to
+// endUserID and returns how many were deleted. A failed delete does not stop
+// the others; all failures are returned together.
+func (s *MCPService) DeleteConnectionsForEndUser(ctx context.Context, endUserID string) (int, error) {
+	if endUserID == "" {
+		return 0, &ValidationError{Field: "endUserID", Message: "must not be empty"}
+	}
+	resp, err := s.ListConnections(ctx, &ConnectionParams{EndUserID: endUserID})
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	var errs []error
+	for _, conn := range resp.Connections {
+		if conn.EndUserID != nil && *conn.EndUserID != endUserID {
+			continue
+		}
+		if err := s.DeleteConnection(ctx, conn.ID); err != nil {
+			errs = append(errs, fmt.Errorf("splox: delete connection %s: %w", conn.ID, err))
+			continue
+		}
+		deleted++
+	}
+	return deleted, errors.Join(errs...)
+}
+
+// Poll intervals for [MCPService.WaitForConnection]. The interval starts at
+// the minimum and doubles after each poll up to the maximum.
+var (
+	connectionPollMinInterval = time.Second
+	connectionPollMaxInterval = 10 * time.Second
+)
+
+// WaitForConnection polls ListConnections until endUserID has a connection
+// to mcpServerID whose Status is "connected" (or unreported), and returns it.
+// Transient errors, as reported by [IsRetryable], are retried; others are
+// returned immediately. It returns a [*TimeoutError] if no connection is
+// active within timeout.
+func (s *MCPService) WaitForConnection(ctx context.Context, mcpServerID, endUserID string, timeout time.Duration) (*MCPConnection, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	params := &ConnectionParams{MCPServerID: mcpServerID, EndUserID: endUserID}
+	interval := connectionPollMinInterval
+	for {
+		resp, err := s.ListConnections(waitCtx, params)
+		if err == nil {
+			for i, conn := range resp.Connections {
+				if conn.EndUserID != nil && *conn.EndUserID != endUserID {
+					continue
+				}
+				if conn.Status == "" || conn.Status == "connected" {
+					return &resp.Connections[i], nil
+				}
+			}
+		} else if waitCtx.Err() == nil && !IsRetryable(err) {
+			return nil, err
+		}
+
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, &TimeoutError{Message: fmt.Sprintf("no active connection to %s for %s within %s", mcpServerID, endUserID, timeout)}
+		case <-time.After(interval):
+		}
+		interval = min(interval*2, connectionPollMaxInterval)
+	}
+}
+
 // ExecuteToolParams are parameters for [MCPService.ExecuteTool].
 type ExecuteToolParams struct {
 	MCPServerID string         `json:"mcp_server_id"`
@@ -123,6 +274,155 @@ func (s *MCPService) ExecuteTool(ctx context.Context, params ExecuteToolParams)
 	return &resp, nil
 }
 
+// ExecuteToolValidated is like [MCPService.ExecuteTool] but first checks
+// params.Args against the tool's input schema. Missing required args or args
+// of the wrong JSON type yield a [*ValidationError] without calling the tool.
+// Tool schemas are fetched once per MCP server and cached on the service.
+func (s *MCPService) ExecuteToolValidated(ctx context.Context, params ExecuteToolParams) (*MCPExecuteToolResponse, error) {
+	tool, err := s.toolSchema(ctx, params.MCPServerID, params.ToolSlug)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateToolArgs(tool, params.Args); err != nil {
+		return nil, err
+	}
+	return s.ExecuteTool(ctx, params)
+}
+
+// toolSchema returns the cached tool definition, refreshing the server's tool
+// list once if the slug is unknown.
+func (s *MCPService) toolSchema(ctx context.Context, mcpServerID, slug string) (MCPTool, error) {
+	s.mu.Lock()
+	tool, ok := s.tools[mcpServerID][slug]
+	s.mu.Unlock()
+	if ok {
+		return tool, nil
+	}
+
+	resp, err := s.GetServerTools(ctx, mcpServerID)
+	if err != nil {
+		return MCPTool{}, err
+	}
+	bySlug := make(map[string]MCPTool, len(resp.Options))
+	for _, t := range resp.Options {
+		bySlug[t.Value] = t
+	}
+
+	s.mu.Lock()
+	if s.tools == nil {
+		s.tools = make(map[string]map[string]MCPTool)
+	}
+	s.tools[mcpServerID] = bySlug
+	s.mu.Unlock()
+
+	tool, ok = bySlug[slug]
+	if !ok {
+		return MCPTool{}, &ValidationError{Field: "tool_slug", Message: fmt.Sprintf("unknown tool %q", slug)}
+	}
+	return tool, nil
+}
+
+// validateToolArgs checks required args and top-level JSON types. Types are
+// checked on args as they will be encoded, so typed slices, maps and structs
+// match "array" and "object" like their untyped equivalents.
+func validateToolArgs(tool MCPTool, args map[string]any) error {
+	for _, name := range tool.RequiredArgs() {
+		if _, ok := args[name]; !ok {
+			return &ValidationError{Field: name, Message: "required argument missing"}
+		}
+	}
+
+	normalized, err := normalizeJSON(args)
+	if err != nil {
+		return err
+	}
+
+	props, _ := tool.InputSchema["properties"].(map[string]any)
+	for name, value := range normalized {
+		prop, _ := props[name].(map[string]any)
+		if prop == nil {
+			continue
+		}
+		var types []string
+		switch t := prop["type"].(type) {
+		case string:
+			types = []string{t}
+		case []any:
+			for _, v := range t {
+				if str, ok := v.(string); ok {
+					types = append(types, str)
+				}
+			}
+		}
+		if len(types) == 0 {
+			continue
+		}
+		matched := false
+		for _, t := range types {
+			if jsonTypeMatches(t, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return &ValidationError{Field: name, Message: fmt.Sprintf("expected %s, got %T", strings.Join(types, " or "), args[name])}
+		}
+	}
+	return nil
+}
+
+// normalizeJSON round-trips args through JSON, so values are decoded as
+// string, bool, json.Number, map[string]any, []any or nil.
+func normalizeJSON(args map[string]any) (map[string]any, error) {
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("splox: encode tool args: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var out map[string]any
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("splox: decode tool args: %w", err)
+	}
+	return out, nil
+}
+
+// jsonTypeMatches reports whether v, as decoded by normalizeJSON, is a valid
+// value for JSON schema type t.
+func jsonTypeMatches(t string, v any) bool {
+	switch t {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		if _, err := n.Int64(); err == nil {
+			return true
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "null":
+		return v == nil
+	default:
+		return true
+	}
+}
+
 // GetServerTools lists tools for a caller-owned MCP server.
 func (s *MCPService) GetServerTools(ctx context.Context, mcpServerID string) (*MCPServerToolsResponse, error) {
 	var resp MCPServerToolsResponse
@@ -132,6 +432,54 @@ func (s *MCPService) GetServerTools(ctx context.Context, mcpServerID string) (*M
 	return &resp, nil
 }
 
+// --------------------------------------------------------------------------
+// User MCP servers
+// --------------------------------------------------------------------------
+
+// CreateServerParams are the parameters for [MCPService.CreateUserServer].
+type CreateServerParams struct {
+	Name          string         `json:"name"`
+	URL           string         `json:"url"`
+	TransportType string         `json:"transport_type,omitempty"` // e.g. "http", "sse"
+	AuthType      string         `json:"auth_type,omitempty"`      // e.g. "none", "api_key", "oauth"
+	AuthConfig    map[string]any `json:"auth_config,omitempty"`
+	ImageURL      string         `json:"image_url,omitempty"`
+}
+
+// UpdateServerParams are the parameters for [MCPService.UpdateUserServer].
+// Only non-nil fields are sent.
+type UpdateServerParams struct {
+	Name          *string        `json:"name,omitempty"`
+	URL           *string        `json:"url,omitempty"`
+	TransportType *string        `json:"transport_type,omitempty"`
+	AuthType      *string        `json:"auth_type,omitempty"`
+	AuthConfig    map[string]any `json:"auth_config,omitempty"`
+	ImageURL      *string        `json:"image_url,omitempty"`
+}
+
+// CreateUserServer registers a new MCP server for the authenticated user.
+func (s *MCPService) CreateUserServer(ctx context.Context, params CreateServerParams) (*UserMCPServer, error) {
+	var resp UserMCPServer
+	if err := s.client.do(ctx, "POST", "/user-mcp-servers", params, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateUserServer updates a caller-owned MCP server.
+func (s *MCPService) UpdateUserServer(ctx context.Context, id string, params UpdateServerParams) (*UserMCPServer, error) {
+	var resp UserMCPServer
+	if err := s.client.do(ctx, "PATCH", "/user-mcp-servers/"+id, params, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteUserServer removes a caller-owned MCP server.
+func (s *MCPService) DeleteUserServer(ctx context.Context, id string) error {
+	return s.client.do(ctx, "DELETE", "/user-mcp-servers/"+id, nil, nil)
+}
+
 // --------------------------------------------------------------------------
 // Connection Token (client-side JWT generation)
 // --------------------------------------------------------------------------
@@ -163,11 +511,6 @@ func base64URLEncode(data []byte) string {
 func GenerateConnectionToken(mcpServerID, ownerUserID, endUserID, credentialsEncryptionKey string) (string, error) {
 	now := time.Now().UTC()
 
-	header := map[string]string{
-		"alg": "HS256",
-		"typ": "JWT",
-	}
-
 	claims := map[string]interface{}{
 		"mcp_server_id": mcpServerID,
 		"owner_user_id": ownerUserID,
@@ -177,6 +520,17 @@ func GenerateConnectionToken(mcpServerID, ownerUserID, endUserID, credentialsEnc
 		"exp":           now.Add(mcpConnectionExpiry).Unix(),
 	}
 
+	return signConnectionToken(claims, credentialsEncryptionKey)
+}
+
+// signConnectionToken encodes claims as an HS256 JWT signed with the key
+// derived from credentialsEncryptionKey.
+func signConnectionToken(claims map[string]interface{}, credentialsEncryptionKey string) (string, error) {
+	header := map[string]string{
+		"alg": "HS256",
+		"typ": "JWT",
+	}
+
 	headerJSON, err := json.Marshal(header)
 	if err != nil {
 		return "", fmt.Errorf("splox: marshal JWT header: %w", err)
@@ -196,6 +550,124 @@ func GenerateConnectionToken(mcpServerID, ownerUserID, endUserID, credentialsEnc
 	return signingInput + "." + signature, nil
 }
 
+// DefaultClockSkew is the clock skew tolerated by [ParseConnectionToken]
+// unless [WithClockSkew] is given.
+const DefaultClockSkew = 60 * time.Second
+
+// ConnectionClaims are the verified claims of a connection token.
+type ConnectionClaims struct {
+	MCPServerID string
+	OwnerUserID string
+	EndUserID   string
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+	NotBefore   time.Time // zero if the token has no nbf claim
+}
+
+// tokenConfig holds settings for [ParseConnectionToken].
+type tokenConfig struct {
+	skew time.Duration
+}
+
+// TokenOption configures [ParseConnectionToken].
+type TokenOption func(*tokenConfig)
+
+// WithClockSkew sets how far the local clock may disagree with the token
+// issuer's: the token is accepted up to d after its exp, and its iat and nbf
+// may be up to d in the future. The default is [DefaultClockSkew]; negative
+// values are treated as zero.
+func WithClockSkew(d time.Duration) TokenOption {
+	return func(c *tokenConfig) { c.skew = max(d, 0) }
+}
+
+// ParseConnectionToken verifies a token created by [GenerateConnectionToken]
+// (or the backend equivalent) and returns its claims. It returns a
+// [*TokenError] if the signature, issuer or timestamps are invalid.
+func ParseConnectionToken(token, credentialsEncryptionKey string, opts ...TokenOption) (*ConnectionClaims, error) {
+	cfg := tokenConfig{skew: DefaultClockSkew}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return parseConnectionToken(token, credentialsEncryptionKey, time.Now(), cfg)
+}
+
+// parseConnectionToken implements ParseConnectionToken, checking timestamps
+// against now.
+func parseConnectionToken(token, credentialsEncryptionKey string, now time.Time, cfg tokenConfig) (*ConnectionClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, &TokenError{Message: "malformed token"}
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeTokenPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Alg != "HS256" {
+		return nil, &TokenError{Message: fmt.Sprintf("unexpected algorithm %q", header.Alg)}
+	}
+
+	mac := hmac.New(sha256.New, deriveSigningKey(credentialsEncryptionKey))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, &TokenError{Message: "signature mismatch"}
+	}
+
+	var claims struct {
+		MCPServerID string `json:"mcp_server_id"`
+		OwnerUserID string `json:"owner_user_id"`
+		EndUserID   string `json:"end_user_id"`
+		Issuer      string `json:"iss"`
+		IssuedAt    int64  `json:"iat"`
+		ExpiresAt   int64  `json:"exp"`
+		NotBefore   int64  `json:"nbf"`
+	}
+	if err := decodeTokenPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if claims.Issuer != mcpConnectionIssuer {
+		return nil, &TokenError{Message: fmt.Sprintf("unexpected issuer %q", claims.Issuer)}
+	}
+
+	out := &ConnectionClaims{
+		MCPServerID: claims.MCPServerID,
+		OwnerUserID: claims.OwnerUserID,
+		EndUserID:   claims.EndUserID,
+		IssuedAt:    time.Unix(claims.IssuedAt, 0).UTC(),
+		ExpiresAt:   time.Unix(claims.ExpiresAt, 0).UTC(),
+	}
+	if claims.NotBefore != 0 {
+		out.NotBefore = time.Unix(claims.NotBefore, 0).UTC()
+	}
+
+	switch {
+	case claims.ExpiresAt == 0:
+		return nil, &TokenError{Message: "missing exp claim"}
+	case now.After(out.ExpiresAt.Add(cfg.skew)):
+		return nil, &TokenError{Message: "token expired"}
+	case out.IssuedAt.After(now.Add(cfg.skew)):
+		return nil, &TokenError{Message: "token issued in the future"}
+	case out.NotBefore.After(now.Add(cfg.skew)):
+		return nil, &TokenError{Message: "token not yet valid"}
+	}
+	return out, nil
+}
+
+// decodeTokenPart decodes one base64url JSON segment of a JWT into v.
+func decodeTokenPart(part string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return &TokenError{Message: "malformed token"}
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return &TokenError{Message: "malformed token"}
+	}
+	return nil
+}
+
 // GenerateConnectionLink builds a full connection URL that end-users can visit
 // to submit their credentials for a specific MCP server.
 //
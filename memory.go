@@ -2,7 +2,10 @@ package splox
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/url"
 )
 
@@ -71,6 +74,19 @@ type MemoryActionResponse struct {
 	Summary        string          `json:"summary,omitempty"`
 	Messages       []MemoryMessage `json:"messages,omitempty"`
 	RemainingCount int             `json:"remaining_count,omitempty"`
+	NextCursor     string          `json:"next_cursor,omitempty"` // export only
+	HasMore        bool            `json:"has_more,omitempty"`    // export only
+}
+
+// MemorySearchHit is a memory message ranked by [MemoryService.Search].
+type MemorySearchHit struct {
+	MemoryMessage
+	Score float64 `json:"score"` // similarity to the query; higher is closer
+}
+
+// MemorySearchResponse is returned by [MemoryService.Search].
+type MemorySearchResponse struct {
+	Results []MemorySearchHit `json:"results"` // best match first
 }
 
 // ── Parameter types ──────────────────────────────────────────────────────────
@@ -115,14 +131,30 @@ type MemoryDeleteParams struct {
 	WorkflowVersionID string // Required
 }
 
+// MemoryAppendParams are parameters for [MemoryService.Append].
+type MemoryAppendParams struct {
+	ContextMemoryID   string // Required
+	WorkflowVersionID string // Required
+	Role              string // Required: "system", "user", "assistant", or "tool"
+	Content           any    // Required: string or structured content parts
+}
+
+// MemorySearchParams are parameters for [MemoryService.Search].
+type MemorySearchParams struct {
+	ContextMemoryID   string // Required
+	WorkflowVersionID string // Required
+	Query             string // Required: text to match semantically
+	TopK              int    // Maximum hits to return (server default if 0)
+}
+
 // ── Methods ──────────────────────────────────────────────────────────────────
 
 // List returns paginated memory instances for a workflow version.
 func (s *MemoryService) List(ctx context.Context, workflowVersionID string, params *MemoryListParams) (*MemoryListResponse, error) {
 	v := url.Values{}
 	if params != nil {
-		if params.Limit > 0 {
-			v.Set("limit", fmt.Sprintf("%d", params.Limit))
+		if err := setLimit(v, params.Limit); err != nil {
+			return nil, err
 		}
 		if params.Cursor != "" {
 			v.Set("cursor", params.Cursor)
@@ -136,6 +168,64 @@ func (s *MemoryService) List(ctx context.Context, workflowVersionID string, para
 	return &resp, nil
 }
 
+// MemoryInstanceIter iterates over memory instances across pages.
+// Call [MemoryInstanceIter.Next] in a loop and check [MemoryInstanceIter.Err] afterwards.
+type MemoryInstanceIter struct {
+	p *pager[MemoryInstance]
+}
+
+// Next advances to the next memory instance.
+func (it *MemoryInstanceIter) Next() bool { return it.p.next() }
+
+// Instance returns the current memory instance. Only valid after [MemoryInstanceIter.Next] returns true.
+func (it *MemoryInstanceIter) Instance() MemoryInstance { return it.p.cur }
+
+// Err returns any error encountered during iteration.
+func (it *MemoryInstanceIter) Err() error { return it.p.err }
+
+// ListAll returns an iterator over every memory instance for a workflow
+// version, following cursors until exhausted. pageSize <= 0 uses the server default.
+func (s *MemoryService) ListAll(ctx context.Context, workflowVersionID string, pageSize int) *MemoryInstanceIter {
+	params := MemoryListParams{Limit: pageSize}
+	return &MemoryInstanceIter{p: newPager(ctx, func(ctx context.Context) ([]MemoryInstance, bool, error) {
+		resp, err := s.List(ctx, workflowVersionID, &params)
+		if err != nil {
+			return nil, false, err
+		}
+		params.Cursor = resp.PageCursor()
+		return resp.Chats, resp.HasNextPage(), nil
+	})}
+}
+
+// MemoryMessageIter iterates over memory messages across pages.
+// Call [MemoryMessageIter.Next] in a loop and check [MemoryMessageIter.Err] afterwards.
+type MemoryMessageIter struct {
+	p *pager[MemoryMessage]
+}
+
+// Next advances to the next memory message.
+func (it *MemoryMessageIter) Next() bool { return it.p.next() }
+
+// Message returns the current memory message. Only valid after [MemoryMessageIter.Next] returns true.
+func (it *MemoryMessageIter) Message() MemoryMessage { return it.p.cur }
+
+// Err returns any error encountered during iteration.
+func (it *MemoryMessageIter) Err() error { return it.p.err }
+
+// GetAll returns an iterator over every memory message for an agent node.
+// ChatID and Limit are taken from params and fixed for all pages; iteration
+// starts at params.Cursor and only the cursor advances.
+func (s *MemoryService) GetAll(ctx context.Context, agentNodeID string, params MemoryGetParams) *MemoryMessageIter {
+	return &MemoryMessageIter{p: newPager(ctx, func(ctx context.Context) ([]MemoryMessage, bool, error) {
+		resp, err := s.Get(ctx, agentNodeID, &params)
+		if err != nil {
+			return nil, false, err
+		}
+		params.Cursor = resp.PageCursor()
+		return resp.Messages, resp.HasNextPage(), nil
+	})}
+}
+
 // Get returns paginated memory messages for an agent node.
 func (s *MemoryService) Get(ctx context.Context, agentNodeID string, params *MemoryGetParams) (*MemoryGetResponse, error) {
 	v := url.Values{}
@@ -143,8 +233,8 @@ func (s *MemoryService) Get(ctx context.Context, agentNodeID string, params *Mem
 		if params.ChatID != "" {
 			v.Set("chat_id", params.ChatID)
 		}
-		if params.Limit > 0 {
-			v.Set("limit", fmt.Sprintf("%d", params.Limit))
+		if err := setLimit(v, params.Limit); err != nil {
+			return nil, err
 		}
 		if params.Cursor != "" {
 			v.Set("cursor", params.Cursor)
@@ -212,21 +302,148 @@ func (s *MemoryService) Clear(ctx context.Context, agentNodeID string, params Me
 	return &resp, nil
 }
 
-// Export returns all memory messages for a memory instance.
+// ClearAll clears every memory instance of a workflow version and returns how
+// many were cleared. This is destructive and cannot be undone. All instances
+// are listed before any is cleared; a failed clear does not stop the others,
+// and all failures are returned together.
+func (s *MemoryService) ClearAll(ctx context.Context, workflowVersionID string) (int, error) {
+	var instances []MemoryInstance
+	iter := s.ListAll(ctx, workflowVersionID, maxPageLimit)
+	for iter.Next() {
+		instances = append(instances, iter.Instance())
+	}
+	if err := iter.Err(); err != nil {
+		return 0, err
+	}
+
+	cleared := 0
+	var errs []error
+	for _, inst := range instances {
+		_, err := s.Clear(ctx, inst.MemoryNodeID, MemoryClearParams{
+			ContextMemoryID:   inst.ChatID,
+			WorkflowVersionID: workflowVersionID,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("splox: clear memory %s: %w", inst.ID, err))
+			continue
+		}
+		cleared++
+	}
+	return cleared, errors.Join(errs...)
+}
+
+// Export returns all memory messages for a memory instance. The export
+// action pages large instances with has_more and next_cursor; Export follows
+// the cursor until the last page and returns every message in one response,
+// with HasMore false. Use [MemoryService.ExportTo] to avoid holding a large
+// instance in memory.
 func (s *MemoryService) Export(ctx context.Context, agentNodeID string, params MemoryExportParams) (*MemoryActionResponse, error) {
+	var out *MemoryActionResponse
+	err := s.exportPages(ctx, agentNodeID, params, func(page *MemoryActionResponse) error {
+		if out == nil {
+			out = page
+		} else {
+			out.Messages = append(out.Messages, page.Messages...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out.HasMore = false
+	out.NextCursor = ""
+	return out, nil
+}
+
+// exportPages calls fn with each page of the export action in order,
+// following next_cursor until the server reports no more pages.
+func (s *MemoryService) exportPages(ctx context.Context, agentNodeID string, params MemoryExportParams, fn func(*MemoryActionResponse) error) error {
 	body := map[string]any{
 		"action":              "export",
 		"context_memory_id":   params.ContextMemoryID,
 		"workflow_version_id": params.WorkflowVersionID,
+		"limit":               maxPageLimit,
+	}
+	for {
+		var resp MemoryActionResponse
+		if err := s.client.do(ctx, "POST", "/chat-memory/"+agentNodeID+"/actions", body, &resp); err != nil {
+			return err
+		}
+		if err := fn(&resp); err != nil {
+			return err
+		}
+		if !resp.HasMore || resp.NextCursor == "" {
+			return nil
+		}
+		if resp.NextCursor == body["cursor"] {
+			return fmt.Errorf("splox: export: server repeated cursor %q", resp.NextCursor)
+		}
+		body["cursor"] = resp.NextCursor
 	}
+}
 
-	var resp MemoryActionResponse
+// Search returns the messages of a memory instance most semantically similar
+// to params.Query, best match first.
+func (s *MemoryService) Search(ctx context.Context, agentNodeID string, params MemorySearchParams) (*MemorySearchResponse, error) {
+	if params.Query == "" {
+		return nil, &ValidationError{Field: "Query", Message: "must not be empty"}
+	}
+	if params.TopK < 0 {
+		return nil, &ValidationError{Field: "TopK", Message: fmt.Sprintf("must not be negative, got %d", params.TopK)}
+	}
+	body := map[string]any{
+		"action":              "search",
+		"context_memory_id":   params.ContextMemoryID,
+		"workflow_version_id": params.WorkflowVersionID,
+		"query":               params.Query,
+	}
+	if params.TopK > 0 {
+		body["top_k"] = params.TopK
+	}
+
+	var resp MemorySearchResponse
 	if err := s.client.do(ctx, "POST", "/chat-memory/"+agentNodeID+"/actions", body, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
+// Append adds a message to a memory instance, e.g. to seed an agent's
+// context before a run starts. It returns the stored message.
+func (s *MemoryService) Append(ctx context.Context, agentNodeID string, params MemoryAppendParams) (*MemoryMessage, error) {
+	body := map[string]any{
+		"action":              "append",
+		"context_memory_id":   params.ContextMemoryID,
+		"workflow_version_id": params.WorkflowVersionID,
+		"role":                params.Role,
+		"content":             params.Content,
+	}
+
+	var resp MemoryActionResponse
+	if err := s.client.do(ctx, "POST", "/chat-memory/"+agentNodeID+"/actions", body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Messages) == 0 {
+		return nil, fmt.Errorf("splox: append: response contained no message")
+	}
+	return &resp.Messages[0], nil
+}
+
+// ExportTo streams every message of a memory instance to w as JSON Lines,
+// one [MemoryMessage] per line. Pages of the export action are written as
+// they arrive, so large instances are never held in memory at once.
+func (s *MemoryService) ExportTo(ctx context.Context, agentNodeID string, params MemoryExportParams, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	return s.exportPages(ctx, agentNodeID, params, func(page *MemoryActionResponse) error {
+		for _, m := range page.Messages {
+			if err := enc.Encode(m); err != nil {
+				return fmt.Errorf("splox: export: write: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
 // Delete removes all memory for a specific memory instance.
 func (s *MemoryService) Delete(ctx context.Context, contextMemoryID string, params MemoryDeleteParams) error {
 	body := map[string]any{
@@ -1,5 +1,12 @@
 package splox
 
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // WorkflowRequestFile represents a file attached to a workflow run request.
 type WorkflowRequestFile struct {
 	URL         string         `json:"url"`
@@ -32,10 +39,19 @@ type WorkflowVersion struct {
 	Metadata      map[string]any `json:"metadata,omitempty"`
 }
 
+// NodeType identifies the kind of a workflow node.
+type NodeType string
+
+// Known node types. The server may return others.
+const (
+	NodeTypeStart NodeType = "start"
+	NodeTypeAgent NodeType = "agent"
+)
+
 type Node struct {
 	ID                string         `json:"id"`
 	WorkflowVersionID string         `json:"workflow_version_id"`
-	NodeType          string         `json:"node_type"`
+	NodeType          NodeType       `json:"node_type"`
 	Label             string         `json:"label"`
 	PosX              *float64       `json:"pos_x,omitempty"`
 	PosY              *float64       `json:"pos_y,omitempty"`
@@ -46,6 +62,47 @@ type Node struct {
 	UpdatedAt         string         `json:"updated_at,omitempty"`
 }
 
+// IsStart reports whether n is a start node.
+func (n Node) IsStart() bool {
+	return n.NodeType == NodeTypeStart
+}
+
+// AgentNodeConfig is the configuration of an agent node, decoded from
+// [Node.Data] by [Node.AgentConfig]. Keys not listed here stay available in
+// Data.
+type AgentNodeConfig struct {
+	Model        string   `json:"model,omitempty"`
+	SystemPrompt string   `json:"system_prompt,omitempty"`
+	Temperature  *float64 `json:"temperature,omitempty"`
+	MaxTokens    *int     `json:"max_tokens,omitempty"`
+}
+
+// AgentConfig returns n's configuration if it is an agent node. ok is false
+// for other node types or if Data does not match [AgentNodeConfig].
+func (n Node) AgentConfig() (cfg *AgentNodeConfig, ok bool) {
+	if n.NodeType != NodeTypeAgent {
+		return nil, false
+	}
+	cfg = &AgentNodeConfig{}
+	if err := n.DecodeData(cfg); err != nil {
+		return nil, false
+	}
+	return cfg, true
+}
+
+// DecodeData decodes n.Data into v, which must be a pointer, as if it were
+// JSON. Use it for node types without a typed accessor.
+func (n Node) DecodeData(v any) error {
+	raw, err := json.Marshal(n.Data)
+	if err != nil {
+		return fmt.Errorf("splox: encode node data: %w", err)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("splox: decode node data: %w", err)
+	}
+	return nil
+}
+
 type Edge struct {
 	ID                string         `json:"id"`
 	WorkflowVersionID string         `json:"workflow_version_id"`
@@ -60,6 +117,22 @@ type Edge struct {
 
 // --- Execution ---
 
+// Status is the status of a workflow request.
+type Status string
+
+// Known workflow request statuses.
+const (
+	StatusInProgress Status = "in_progress"
+	StatusCompleted  Status = "completed"
+	StatusFailed     Status = "failed"
+	StatusStopped    Status = "stopped"
+)
+
+// IsTerminal reports whether s ends a run.
+func (s Status) IsTerminal() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusStopped
+}
+
 type WorkflowRequest struct {
 	ID                      string         `json:"id"`
 	WorkflowVersionID       string         `json:"workflow_version_id"`
@@ -107,7 +180,7 @@ type ExecutionNode struct {
 	NodeID          string           `json:"node_id"`
 	Status          string           `json:"status"`
 	NodeLabel       string           `json:"node_label,omitempty"`
-	NodeType        string           `json:"node_type,omitempty"`
+	NodeType        NodeType         `json:"node_type,omitempty"`
 	InputData       map[string]any   `json:"input_data,omitempty"`
 	OutputData      map[string]any   `json:"output_data,omitempty"`
 	CreatedAt       string           `json:"created_at,omitempty"`
@@ -137,6 +210,7 @@ type Chat struct {
 	ResourceID       string         `json:"resource_id,omitempty"`
 	IsPublic         *bool          `json:"is_public,omitempty"`
 	PublicShareToken string         `json:"public_share_token,omitempty"`
+	PinnedVersionID  string         `json:"pinned_version_id,omitempty"` // Workflow version the chat runs against; empty means latest
 	Metadata         map[string]any `json:"metadata,omitempty"`
 	CreatedAt        string         `json:"created_at,omitempty"`
 	UpdatedAt        string         `json:"updated_at,omitempty"`
@@ -165,6 +239,28 @@ type ChatMessage struct {
 	UpdatedAt string               `json:"updated_at,omitempty"`
 }
 
+// Text returns the concatenation of all "text" content parts.
+func (m ChatMessage) Text() string {
+	var sb strings.Builder
+	for _, c := range m.Content {
+		if c.Type == "text" {
+			sb.WriteString(c.Text)
+		}
+	}
+	return sb.String()
+}
+
+// ToolCalls returns only the "tool-call" content parts.
+func (m ChatMessage) ToolCalls() []ChatMessageContent {
+	var calls []ChatMessageContent
+	for _, c := range m.Content {
+		if c.Type == "tool-call" {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
 // --- Pagination ---
 
 type Pagination struct {
@@ -187,7 +283,8 @@ type Pagination struct {
 //   - "tool_approval_request": Approval needed (ToolName, ToolCallID, ToolArgs)
 //   - "tool_approval_response": Approval result (ToolName, ToolCallID, Approved)
 //   - "user_message": Voice transcript (Text)
-//   - "done": Iteration complete
+//   - "progress": Long-running node progress (NodeID, ProgressPercent)
+//   - "done": Iteration complete (FinishReason, Usage, CostMicrodollars)
 //   - "stopped": User stopped workflow
 //   - "error": Error occurred (Error)
 type SSEEvent struct {
@@ -195,6 +292,7 @@ type SSEEvent struct {
 	NodeExecution   *NodeExecution   `json:"node_execution,omitempty"`
 	IsKeepalive     bool             `json:"-"`
 	RawData         string           `json:"-"`
+	ID              string           `json:"-"` // SSE event ID, if the server sent one
 
 	// Event type and metadata
 	EventType string `json:"type,omitempty"`
@@ -218,10 +316,44 @@ type SSEEvent struct {
 	// Tool approval
 	Approved *bool `json:"approved,omitempty"`
 
+	// Node progress (0-100)
+	NodeID          string `json:"node_id,omitempty"`
+	ProgressPercent *int   `json:"progress,omitempty"`
+
 	// Messages and errors
 	Text    string `json:"text,omitempty"`
 	Message string `json:"message,omitempty"`
 	Error   string `json:"error,omitempty"`
+
+	// Final iteration metadata (done events)
+	FinishReason     string      `json:"finish_reason,omitempty"` // e.g. "stop", "length", "tool"
+	Usage            *TokenUsage `json:"usage,omitempty"`
+	CostMicrodollars *int64      `json:"cost_microdollars,omitempty"` // iteration cost in millionths of a USD
+}
+
+// IsError reports whether the event is a server-sent error event.
+func (e SSEEvent) IsError() bool {
+	return e.EventType == "error"
+}
+
+// errorMessage returns the message carried by an error event.
+func (e SSEEvent) errorMessage() string {
+	if e.Error != "" {
+		return e.Error
+	}
+	return e.Message
+}
+
+// IsProgress reports whether the event is a node progress update.
+func (e SSEEvent) IsProgress() bool {
+	return e.EventType == "progress" && e.ProgressPercent != nil
+}
+
+// TokenUsage holds token counts reported for a single iteration.
+type TokenUsage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+	TotalTokens  int64 `json:"total_tokens"`
 }
 
 // --- Response types ---
@@ -238,10 +370,57 @@ type WorkflowFullResponse struct {
 	Edges           []Edge          `json:"edges"`
 }
 
+// MarshalCanonical returns a stable, indented JSON encoding of the workflow
+// with nodes and edges sorted by ID, suitable for version control and diffs.
+// The receiver is not modified.
+func (r *WorkflowFullResponse) MarshalCanonical() ([]byte, error) {
+	c := *r
+	c.Nodes = append([]Node(nil), r.Nodes...)
+	c.Edges = append([]Edge(nil), r.Edges...)
+	sort.SliceStable(c.Nodes, func(i, j int) bool { return c.Nodes[i].ID < c.Nodes[j].ID })
+	sort.SliceStable(c.Edges, func(i, j int) bool { return c.Edges[i].ID < c.Edges[j].ID })
+
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("splox: marshal workflow: %w", err)
+	}
+	return append(b, '\n'), nil
+}
+
+// StartNodes returns the workflow's start nodes, in their original order.
+func (r *WorkflowFullResponse) StartNodes() []Node {
+	var nodes []Node
+	for _, n := range r.Nodes {
+		if n.IsStart() {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
 type EntryNodesResponse struct {
 	Nodes []Node `json:"nodes"`
 }
 
+// Single returns the only entry node, or a [*ValidationError] listing the
+// nodes if there are none or several.
+func (r *EntryNodesResponse) Single() (*Node, error) {
+	switch len(r.Nodes) {
+	case 0:
+		return nil, &ValidationError{Field: "entry_node_ids", Message: "version has no entry nodes"}
+	case 1:
+		return &r.Nodes[0], nil
+	}
+	labels := make([]string, len(r.Nodes))
+	for i, n := range r.Nodes {
+		labels[i] = fmt.Sprintf("%q (%s)", n.Label, n.ID)
+	}
+	return nil, &ValidationError{
+		Field:   "entry_node_ids",
+		Message: fmt.Sprintf("expected one entry node, got %d: %s", len(r.Nodes), strings.Join(labels, ", ")),
+	}
+}
+
 type WorkflowVersionListResponse struct {
 	Versions []WorkflowVersion `json:"versions"`
 }
@@ -250,6 +429,15 @@ type RunResponse struct {
 	WorkflowRequestID string `json:"workflow_request_id"`
 }
 
+// CostEstimate is the expected cost range of a run and the token counts the
+// estimate assumes.
+type CostEstimate struct {
+	MinUSD       float64 `json:"min_usd"`
+	MaxUSD       float64 `json:"max_usd"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+}
+
 type ExecutionTreeResponse struct {
 	ExecutionTree ExecutionTree `json:"execution_tree"`
 }
@@ -259,8 +447,15 @@ type HistoryResponse struct {
 	Pagination Pagination        `json:"pagination"`
 }
 
+type NodeExecutionListResponse struct {
+	NodeExecutions []NodeExecution `json:"node_executions"`
+	Pagination     Pagination      `json:"pagination"`
+}
+
 type ChatListResponse struct {
-	Chats []Chat `json:"chats"`
+	Chats      []Chat `json:"chats"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
 }
 
 type ChatHistoryResponse struct {
@@ -271,6 +466,7 @@ type ChatHistoryResponse struct {
 type EventResponse struct {
 	OK      bool   `json:"ok"`
 	EventID string `json:"event_id"`
+	DryRun  bool   `json:"dry_run,omitempty"` // true if no run was started
 }
 
 // --- Billing / Cost Tracking ---
@@ -332,6 +528,26 @@ type DailyActivityResponse struct {
 	Days int             `json:"days"`
 }
 
+// RequestCost is the cost of a single workflow request, as returned by
+// [BillingService.GetRequestCost].
+type RequestCost struct {
+	WorkflowRequestID string     `json:"workflow_request_id"`
+	TotalCostUSD      float64    `json:"total_cost_usd"`
+	InputTokens       int64      `json:"input_tokens"`
+	OutputTokens      int64      `json:"output_tokens"`
+	Nodes             []NodeCost `json:"nodes"`
+}
+
+// NodeCost is the cost of one node execution within a workflow request.
+type NodeCost struct {
+	NodeID       string   `json:"node_id"`
+	NodeLabel    string   `json:"node_label,omitempty"`
+	NodeType     NodeType `json:"node_type,omitempty"`
+	CostUSD      float64  `json:"cost_usd"`
+	InputTokens  int64    `json:"input_tokens"`
+	OutputTokens int64    `json:"output_tokens"`
+}
+
 // --- MCP Catalog ---
 
 type MCPCatalogItem struct {
@@ -374,7 +590,9 @@ type MCPConnection struct {
 	AuthType      string         `json:"auth_type"`
 	AuthConfig    map[string]any `json:"auth_config,omitempty"`
 	EndUserID     *string        `json:"end_user_id,omitempty"`
+	Status        string         `json:"status,omitempty"` // e.g. "connected", "expired", "revoked"
 	CreatedAt     string         `json:"created_at"`
+	LastUsedAt    string         `json:"last_used_at,omitempty"`
 }
 
 type MCPConnectionListResponse struct {
@@ -382,6 +600,22 @@ type MCPConnectionListResponse struct {
 	Total       int             `json:"total"`
 }
 
+// --- User MCP Servers ---
+
+// UserMCPServer is an MCP server registered by the authenticated user.
+type UserMCPServer struct {
+	ID            string         `json:"id"`
+	UserID        string         `json:"user_id"`
+	Name          string         `json:"name"`
+	URL           string         `json:"url"`
+	ImageURL      *string        `json:"image_url,omitempty"`
+	TransportType string         `json:"transport_type"`
+	AuthType      string         `json:"auth_type"`
+	AuthConfig    map[string]any `json:"auth_config,omitempty"`
+	CreatedAt     string         `json:"created_at"`
+	UpdatedAt     string         `json:"updated_at,omitempty"`
+}
+
 type MCPExecuteToolResult struct {
 	Content           []map[string]any `json:"content,omitempty"`
 	StructuredContent any              `json:"structuredContent,omitempty"`
@@ -393,15 +627,61 @@ type MCPExecuteToolResponse struct {
 	IsError bool                 `json:"is_error"`
 }
 
-type MCPServerToolOption struct {
-	Label string `json:"label"`
-	Value string `json:"value"`
-}
+// TextContent concatenates the "text" blocks of the MCP result content,
+// skipping other block types. It returns an error if a text block has no
+// string text.
+func (r *MCPExecuteToolResponse) TextContent() (string, error) {
+	var sb strings.Builder
+	for i, block := range r.Result.Content {
+		if block["type"] != "text" {
+			continue
+		}
+		text, ok := block["text"].(string)
+		if !ok {
+			return "", fmt.Errorf("splox: MCP content block %d: text is %T, not a string", i, block["text"])
+		}
+		sb.WriteString(text)
+	}
+	return sb.String(), nil
+}
+
+// Failed reports whether the tool call failed, as signaled either by the API
+// or by the MCP result's isError flag.
+func (r *MCPExecuteToolResponse) Failed() bool {
+	return r.IsError || r.Result.IsError
+}
+
+// MCPTool describes a tool exposed by an MCP server.
+// Value is the tool slug passed to [MCPService.ExecuteTool].
+type MCPTool struct {
+	Label       string         `json:"label"`
+	Value       string         `json:"value"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema,omitempty"` // JSON schema for the tool's args
+}
+
+// RequiredArgs returns the argument names listed in the input schema's
+// "required" array.
+func (t MCPTool) RequiredArgs() []string {
+	raw, _ := t.InputSchema["required"].([]any)
+	required := make([]string, 0, len(raw))
+	for _, r := range raw {
+		if name, ok := r.(string); ok {
+			required = append(required, name)
+		}
+	}
+	return required
+}
+
+// MCPServerToolOption is the former name of [MCPTool].
+//
+// Deprecated: use MCPTool.
+type MCPServerToolOption = MCPTool
 
 type MCPServerToolsResponse struct {
-	Options []MCPServerToolOption `json:"options"`
-	Total   int                   `json:"total"`
-	Limit   int                   `json:"limit"`
+	Options []MCPTool `json:"options"`
+	Total   int       `json:"total"`
+	Limit   int       `json:"limit"`
 }
 
 // --- Workflow Secrets ---
@@ -435,6 +715,9 @@ type GenerateSecretsLinkResponse struct {
 type SecretActionResponse struct {
 	Success bool   `json:"success"`
 	Key     string `json:"key"`
+
+	// Keys lists the secrets written by [WorkflowService.SetEnvSecrets].
+	Keys []string `json:"keys,omitempty"`
 }
 
 // SetEnvSecretParams are the parameters for setting an env-type secret.
@@ -0,0 +1,119 @@
+package splox
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// pager walks a paginated endpoint one item at a time. fetch is called for
+// each page and reports whether more pages follow.
+type pager[T any] struct {
+	ctx   context.Context
+	fetch func(ctx context.Context) (items []T, more bool, err error)
+
+	buf  []T
+	cur  T
+	more bool
+	err  error
+}
+
+func newPager[T any](ctx context.Context, fetch func(ctx context.Context) ([]T, bool, error)) *pager[T] {
+	return &pager[T]{ctx: ctx, fetch: fetch, more: true}
+}
+
+// next advances to the next item, fetching a new page when the buffer is empty.
+func (p *pager[T]) next() bool {
+	if p.err != nil {
+		return false
+	}
+	for len(p.buf) == 0 {
+		if !p.more {
+			return false
+		}
+		if err := p.ctx.Err(); err != nil {
+			p.err = err
+			return false
+		}
+		items, more, err := p.fetch(p.ctx)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.buf, p.more = items, more
+		if len(items) == 0 {
+			p.more = false
+		}
+	}
+	p.cur, p.buf = p.buf[0], p.buf[1:]
+	return true
+}
+
+// Pager is implemented by paginated list responses, so one loop can walk
+// any of them. PageCursor is the value to pass as the next request's Cursor
+// (for page-numbered endpoints, its Page); it is only meaningful when
+// HasNextPage is true. The methods are not named NextCursor and HasMore
+// because several responses already have fields with those names.
+type Pager interface {
+	PageCursor() string
+	HasNextPage() bool
+}
+
+var (
+	_ Pager = (*WorkflowListResponse)(nil)
+	_ Pager = (*HistoryResponse)(nil)
+	_ Pager = (*NodeExecutionListResponse)(nil)
+	_ Pager = (*ChatListResponse)(nil)
+	_ Pager = (*MemoryListResponse)(nil)
+	_ Pager = (*MemoryGetResponse)(nil)
+	_ Pager = (*TransactionHistoryResponse)(nil)
+	_ Pager = (*MCPCatalogListResponse)(nil)
+)
+
+func (r *WorkflowListResponse) PageCursor() string { return r.Pagination.NextCursor }
+func (r *WorkflowListResponse) HasNextPage() bool  { return r.Pagination.hasNext() }
+
+func (r *HistoryResponse) PageCursor() string { return r.Pagination.NextCursor }
+func (r *HistoryResponse) HasNextPage() bool  { return r.Pagination.hasNext() }
+
+func (r *NodeExecutionListResponse) PageCursor() string { return r.Pagination.NextCursor }
+func (r *NodeExecutionListResponse) HasNextPage() bool  { return r.Pagination.hasNext() }
+
+func (r *ChatListResponse) PageCursor() string { return r.NextCursor }
+func (r *ChatListResponse) HasNextPage() bool  { return r.HasMore && r.NextCursor != "" }
+
+func (r *MemoryListResponse) PageCursor() string { return r.NextCursor }
+func (r *MemoryListResponse) HasNextPage() bool  { return r.HasMore && r.NextCursor != "" }
+
+func (r *MemoryGetResponse) PageCursor() string { return r.NextCursor }
+func (r *MemoryGetResponse) HasNextPage() bool  { return r.HasMore && r.NextCursor != "" }
+
+func (r *TransactionHistoryResponse) PageCursor() string {
+	return strconv.Itoa(r.Pagination.Page + 1)
+}
+func (r *TransactionHistoryResponse) HasNextPage() bool { return r.Pagination.HasNext }
+
+func (r *MCPCatalogListResponse) PageCursor() string { return strconv.Itoa(r.CurrentPage + 1) }
+func (r *MCPCatalogListResponse) HasNextPage() bool  { return r.CurrentPage < r.TotalPages }
+
+// hasNext reports whether a further page can be requested.
+func (p Pagination) hasNext() bool { return p.HasMore && p.NextCursor != "" }
+
+// maxPageLimit is the largest page size the API accepts.
+const maxPageLimit = 100
+
+// setLimit sets the "limit" query parameter, capping it at maxPageLimit.
+// Zero leaves the server default; a negative limit is a [*ValidationError].
+func setLimit(v url.Values, limit int) error {
+	if limit < 0 {
+		return &ValidationError{Field: "Limit", Message: fmt.Sprintf("must not be negative, got %d", limit)}
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	if limit > 0 {
+		v.Set("limit", fmt.Sprintf("%d", limit))
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package splox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurstThenWait(t *testing.T) {
+	l := newRateLimiter(20, 3)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.wait(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("expected burst to pass immediately, took %s", elapsed)
+	}
+
+	if err := l.wait(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("expected fourth call to wait about 50ms, took %s", elapsed)
+	}
+}
+
+func TestRateLimiterContextCancel(t *testing.T) {
+	l := newRateLimiter(1, 1)
+	if err := l.wait(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < -0.5 {
+		t.Errorf("expected the reserved token to be returned, bucket at %v", tokens)
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"chat-001"}`))
+	}))
+	defer srv.Close()
+	client := NewClient("key", WithBaseURL(srv.URL), WithRateLimit(50, 2))
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Chats.Get(context.Background(), "chat-001"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 2 requests pass on the burst; the other 3 are spaced 20ms apart.
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected requests to be throttled, took %s", elapsed)
+	}
+}
@@ -0,0 +1,117 @@
+// Package sploxtest provides helpers for unit testing code that uses the
+// Splox SDK without a network or an httptest.Server.
+//
+//	mock := sploxtest.NewMockTransport()
+//	mock.RespondJSON("GET", "/chats/chat-1", 200, splox.Chat{ID: "chat-1"})
+//	client := mock.Client()
+//
+//	chat, err := client.Chats.Get(ctx, "chat-1")
+package sploxtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	splox "github.com/splox-ai/go-sdk"
+)
+
+// BaseURL is the base URL used by [MockTransport.Client]. Requests made by
+// that client have paths exactly as the SDK documents them, e.g. "/workflows".
+const BaseURL = "http://splox.test"
+
+// MockTransport is an [http.RoundTripper] that serves registered responses by
+// method and URL path, ignoring the query string. Requests without a
+// registered response get a 404 that names the missing route.
+//
+// Use it with [splox.WithRoundTripper], or via [MockTransport.Client]. It is
+// safe for concurrent use.
+type MockTransport struct {
+	mu       sync.Mutex
+	handlers map[string]http.Handler
+	calls    map[string]int
+}
+
+// NewMockTransport returns a MockTransport with no registered responses.
+func NewMockTransport() *MockTransport {
+	return &MockTransport{
+		handlers: map[string]http.Handler{},
+		calls:    map[string]int{},
+	}
+}
+
+// Handle serves requests for method and path with h, replacing any earlier
+// registration. The response is buffered, so h may write a complete SSE
+// stream for methods like Workflows.Listen.
+func (m *MockTransport) Handle(method, path string, h http.HandlerFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers[routeKey(method, path)] = h
+}
+
+// Respond serves body with status for method and path.
+func (m *MockTransport) Respond(method, path string, status int, body string) {
+	m.Handle(method, path, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	})
+}
+
+// RespondJSON serves v encoded as JSON with status for method and path. It
+// panics if v cannot be encoded.
+func (m *MockTransport) RespondJSON(method, path string, status int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("sploxtest: encode response for %s %s: %v", method, path, err))
+	}
+	m.Respond(method, path, status, string(body))
+}
+
+// Calls returns how many requests were made for method and path.
+func (m *MockTransport) Calls(method, path string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[routeKey(method, path)]
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := routeKey(req.Method, req.URL.Path)
+
+	m.mu.Lock()
+	h, ok := m.handlers[key]
+	m.calls[key]++
+	m.mu.Unlock()
+
+	if !ok {
+		h = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "sploxtest: no response registered for " + key,
+			})
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// Client returns a [splox.Client] that sends every request to m. opts are
+// applied first, so they cannot replace the base URL or transport.
+func (m *MockTransport) Client(opts ...splox.Option) *splox.Client {
+	opts = append(opts[:len(opts):len(opts)], splox.WithBaseURL(BaseURL), splox.WithRoundTripper(m))
+	return splox.NewClient("sploxtest-key", opts...)
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
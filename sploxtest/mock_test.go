@@ -0,0 +1,63 @@
+package sploxtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	splox "github.com/splox-ai/go-sdk"
+)
+
+func TestMockTransport(t *testing.T) {
+	mock := NewMockTransport()
+	mock.RespondJSON("GET", "/workflows/wf-1", 200, splox.WorkflowFullResponse{
+		Workflow: splox.Workflow{ID: "wf-1", UserID: "user-1"},
+	})
+	mock.Respond("DELETE", "/chats/chat-1", 403, `{"error":"not yours"}`)
+	client := mock.Client()
+
+	wf, err := client.Workflows.Get(context.Background(), "wf-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wf.Workflow.UserID != "user-1" {
+		t.Errorf("expected user-1, got %s", wf.Workflow.UserID)
+	}
+	if n := mock.Calls("GET", "/workflows/wf-1"); n != 1 {
+		t.Errorf("expected 1 call, got %d", n)
+	}
+
+	var forbidden *splox.ForbiddenError
+	if err := client.Chats.Delete(context.Background(), "chat-1"); !errors.As(err, &forbidden) {
+		t.Errorf("expected ForbiddenError, got %v", err)
+	}
+
+	var notFound *splox.NotFoundError
+	if _, err := client.Workflows.Get(context.Background(), "missing"); !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError for unregistered route, got %v", err)
+	}
+}
+
+func TestMockTransportStream(t *testing.T) {
+	mock := NewMockTransport()
+	mock.Handle("GET", "/workflow-requests/req-1/listen", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintln(w, `data: {"type":"text_delta","delta":"hi"}`)
+		fmt.Fprintln(w)
+	})
+
+	iter, err := mock.Client().Workflows.Listen(context.Background(), "req-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+
+	if !iter.Next() {
+		t.Fatalf("expected an event, got err %v", iter.Err())
+	}
+	if iter.Event().TextDelta != "hi" {
+		t.Errorf("expected hi, got %q", iter.Event().TextDelta)
+	}
+}
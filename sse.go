@@ -4,28 +4,66 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // SSEIter reads Server-Sent Events from a stream.
 // Call [SSEIter.Next] in a loop and [SSEIter.Close] when done.
 type SSEIter struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	stop    func() bool // unregisters the close-on-cancel hook
 	resp    *http.Response
 	scanner *bufio.Scanner
 	err     error
 	event   SSEEvent
+	lastID  string
+	span    Span // nil unless tracing is enabled
+
+	useNumber bool // decode numbers in untyped fields as json.Number
+
+	retry    time.Duration // from the last valid "retry:" line
+	hasRetry bool
+
+	merged *fanIn // set for iterators that merge several streams
 }
 
 // Next advances to the next SSE event. Returns false when the stream
 // ends or an error occurs (check [SSEIter.Err]).
 func (it *SSEIter) Next() bool {
+	if it.merged != nil {
+		ev, ok := <-it.merged.events
+		if !ok {
+			it.err = it.merged.error()
+			return false
+		}
+		it.event = ev
+		return true
+	}
+
 	for it.scanner.Scan() {
 		line := strings.TrimSpace(it.scanner.Text())
 		if line == "" {
 			continue
 		}
+		if strings.HasPrefix(line, "id:") {
+			it.lastID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+			continue
+		}
+		if v, ok := strings.CutPrefix(line, "retry:"); ok {
+			// Per the SSE spec, values that aren't plain integers are ignored.
+			if ms, err := strconv.ParseUint(strings.TrimSpace(v), 10, 32); err == nil {
+				it.retry, it.hasRetry = time.Duration(ms)*time.Millisecond, true
+			}
+			continue
+		}
 		if !strings.HasPrefix(line, "data:") {
 			continue
 		}
@@ -33,27 +71,48 @@ func (it *SSEIter) Next() bool {
 		payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
 
 		if payload == "keepalive" {
-			it.event = SSEEvent{IsKeepalive: true, RawData: payload}
+			it.event = SSEEvent{IsKeepalive: true, RawData: payload, ID: it.lastID}
 			return true
 		}
 
 		var ev SSEEvent
-		if err := json.Unmarshal([]byte(payload), &ev); err != nil {
-			it.event = SSEEvent{RawData: payload}
+		dec := json.NewDecoder(strings.NewReader(payload))
+		if it.useNumber {
+			dec.UseNumber()
+		}
+		if err := dec.Decode(&ev); err != nil {
+			it.event = SSEEvent{RawData: payload, ID: it.lastID}
 			return true
 		}
 
 		ev.RawData = payload
+		ev.ID = it.lastID
 		it.event = ev
 		return true
 	}
 
-	if err := it.scanner.Err(); err != nil {
+	if it.ctx != nil && it.ctx.Err() != nil {
+		it.err = &StreamError{Err: it.ctx.Err()}
+	} else if err := it.scanner.Err(); err != nil {
 		it.err = &StreamError{Err: err}
 	}
 	return false
 }
 
+// LastEventID returns the most recent event ID sent by the server, or "" if
+// none. Persist it and pass it to [WorkflowService.ListenFrom] to resume.
+func (it *SSEIter) LastEventID() string {
+	return it.lastID
+}
+
+// RetryDelay returns the reconnect delay most recently suggested by the
+// server with a "retry:" line. ok is false if the server sent none; callers
+// reconnecting with [WorkflowService.ListenFrom] should then use their own
+// backoff.
+func (it *SSEIter) RetryDelay() (d time.Duration, ok bool) {
+	return it.retry, it.hasRetry
+}
+
 // Event returns the current SSE event. Only valid after [Next] returns true.
 func (it *SSEIter) Event() SSEEvent {
 	return it.event
@@ -66,41 +125,227 @@ func (it *SSEIter) Err() error {
 
 // Close releases the underlying HTTP response.
 func (it *SSEIter) Close() error {
+	if it.stop != nil {
+		it.stop()
+	}
+	if it.cancel != nil {
+		it.cancel()
+	}
+	if it.span != nil {
+		if it.err != nil {
+			it.span.RecordError(it.err)
+		}
+		it.span.End()
+		it.span = nil
+	}
+	if it.merged != nil {
+		it.merged.wait()
+	}
 	if it.resp != nil {
 		return it.resp.Body.Close()
 	}
 	return nil
 }
 
+// fanIn merges events from several SSE streams into one channel. Each source
+// is pumped by its own goroutine; events is closed once every source ends.
+type fanIn struct {
+	ctx    context.Context
+	events chan SSEEvent
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+}
+
+func newFanIn(ctx context.Context) *fanIn {
+	return &fanIn{ctx: ctx, events: make(chan SSEEvent)}
+}
+
+// add starts pumping iter. annotate, if non-nil, may modify each event and
+// is called on the pumping goroutine. Sources must be added before
+// closeWhenDone or from within another source's annotate callback.
+func (f *fanIn) add(iter *SSEIter, annotate func(*SSEEvent)) {
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		defer iter.Close()
+		for iter.Next() {
+			ev := iter.Event()
+			if annotate != nil {
+				annotate(&ev)
+			}
+			select {
+			case f.events <- ev:
+			case <-f.ctx.Done():
+				return
+			}
+		}
+		if err := iter.Err(); err != nil {
+			f.fail(err)
+		}
+	}()
+}
+
+// fail records an error to report once the merged stream ends.
+func (f *fanIn) fail(err error) {
+	f.mu.Lock()
+	f.errs = append(f.errs, err)
+	f.mu.Unlock()
+}
+
+func (f *fanIn) error() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return errors.Join(f.errs...)
+}
+
+// closeWhenDone closes events after every source has ended.
+func (f *fanIn) closeWhenDone() {
+	go func() {
+		f.wg.Wait()
+		close(f.events)
+	}()
+}
+
+// wait blocks until every source goroutine has exited.
+func (f *fanIn) wait() {
+	f.wg.Wait()
+}
+
+// newStreamClient returns an *http.Client for long-lived SSE streams: it has
+// no overall timeout, and when the transport is an *http.Transport its
+// ResponseHeaderTimeout is set to headerTimeout so a dead endpoint fails fast.
+// The transport is cloned once and then shared by every stream.
+func newStreamClient(rt http.RoundTripper, headerTimeout time.Duration) *http.Client {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	if t, ok := rt.(*http.Transport); ok {
+		t = t.Clone()
+		t.ResponseHeaderTimeout = headerTimeout
+		rt = t
+	}
+	return &http.Client{Transport: rt}
+}
+
+// StreamRaw opens an SSE stream at path, relative to the base URL, and
+// returns its undecoded body for use with a custom SSE parser, e.g. for event
+// types [SSEEvent] doesn't model yet. Connecting works as for typed streams:
+// auth, headers, the connect timeout, and typed errors all apply. The caller
+// must close the returned body.
+func (c *Client) StreamRaw(ctx context.Context, path string, opts ...RequestOption) (io.ReadCloser, error) {
+	iter, err := c.streamSSE(ctx, path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return rawStream{iter}, nil
+}
+
+// rawStream exposes the body of an SSE connection while keeping the
+// iterator's cleanup (context, span) on Close.
+type rawStream struct{ it *SSEIter }
+
+func (r rawStream) Read(p []byte) (int, error) { return r.it.resp.Body.Read(p) }
+func (r rawStream) Close() error               { return r.it.Close() }
+
 // streamSSE opens an SSE connection and returns an iterator.
-func (c *Client) streamSSE(ctx context.Context, path string) (*SSEIter, error) {
-	u := c.baseURL + path
+func (c *Client) streamSSE(ctx context.Context, path string, opts ...RequestOption) (*SSEIter, error) {
+	u := c.url(path)
+
+	var rc requestConfig
+	for _, opt := range opts {
+		opt(&rc)
+	}
+
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, span := c.startSpan(ctx, http.MethodGet, path)
+	iter, err := c.openSSE(ctx, u, rc.headers, span)
+	if err != nil {
+		if span != nil {
+			span.RecordError(err)
+			span.End()
+		}
+		return nil, err
+	}
+	iter.span = span
+	return iter, nil
+}
+
+// openSSE performs the SSE request with optional extra headers. span may be nil.
+//
+// The stream connect timeout covers dialing, TLS, and waiting for response
+// headers; once the response arrives the timer is stopped so the body can be
+// read for as long as the stream lives.
+func (c *Client) openSSE(ctx context.Context, u string, headers map[string]string, span Span) (*SSEIter, error) {
+	ctx, cancel := context.WithCancel(ctx)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("splox: create SSE request: %w", err)
 	}
 
 	req.Header.Set("Accept", "text/event-stream")
-	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if err := c.setCommonHeaders(ctx, req); err != nil {
+		cancel()
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if err := c.intercept(req); err != nil {
+		cancel()
+		return nil, err
 	}
 
-	// Use a client without timeout for long-lived SSE streams.
-	sseClient := &http.Client{Transport: c.httpClient.Transport}
-
-	resp, err := sseClient.Do(req)
+	var timer *time.Timer
+	if c.streamConnectTimeout > 0 {
+		timer = time.AfterFunc(c.streamConnectTimeout, cancel)
+	}
+	resp, err := c.sseClient.Do(req)
+	timedOut := timer != nil && !timer.Stop()
+	if err == nil && timedOut {
+		resp.Body.Close()
+		err = context.DeadlineExceeded
+	}
 	if err != nil {
+		cancel()
+		err = redactURLError(err)
+		if timedOut {
+			err = fmt.Errorf("stream connect timed out after %s: %w", c.streamConnectTimeout, err)
+		}
 		return nil, &ConnectionError{Err: err}
 	}
 
+	if span != nil {
+		span.SetAttribute(AttrHTTPStatusCode, resp.StatusCode)
+		if id := resp.Header.Get(requestIDHeader); id != "" {
+			span.SetAttribute(AttrRequestID, id)
+		}
+	}
+
 	if err := checkStatus(resp); err != nil {
 		resp.Body.Close()
+		cancel()
 		return nil, err
 	}
 
+	// Closing the body unblocks a pending read as soon as ctx is done, even
+	// with transports that don't honor request cancellation mid-body.
+	stop := context.AfterFunc(ctx, func() { resp.Body.Close() })
+
 	return &SSEIter{
-		resp:    resp,
-		scanner: bufio.NewScanner(resp.Body),
+		ctx:       ctx,
+		cancel:    cancel,
+		stop:      stop,
+		resp:      resp,
+		scanner:   bufio.NewScanner(resp.Body),
+		useNumber: c.useJSONNumber,
 	}, nil
 }
@@ -1,10 +1,15 @@
 package splox
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestSSEIterKeepalive(t *testing.T) {
@@ -87,6 +92,67 @@ func TestSSEIterNodeExecution(t *testing.T) {
 	}
 }
 
+func TestSSEIterDoneEvent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintln(w, `data: {"type":"done","finish_reason":"length","usage":{"input_tokens":120,"output_tokens":30,"total_tokens":150},"cost_microdollars":4200}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient("key", WithBaseURL(srv.URL))
+	iter, err := client.streamSSE(t.Context(), "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+
+	if !iter.Next() {
+		t.Fatal("expected event")
+	}
+	ev := iter.Event()
+	if ev.EventType != "done" {
+		t.Errorf("expected done, got %s", ev.EventType)
+	}
+	if ev.FinishReason != "length" {
+		t.Errorf("expected length, got %s", ev.FinishReason)
+	}
+	if ev.Usage == nil {
+		t.Fatal("expected usage")
+	}
+	if ev.Usage.TotalTokens != 150 {
+		t.Errorf("expected 150 total tokens, got %d", ev.Usage.TotalTokens)
+	}
+	if ev.CostMicrodollars == nil || *ev.CostMicrodollars != 4200 {
+		t.Errorf("expected cost 4200, got %v", ev.CostMicrodollars)
+	}
+}
+
+func TestSSEIterProgressEvent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintln(w, `data: {"type":"progress","node_id":"n1","progress":42}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient("key", WithBaseURL(srv.URL))
+	iter, err := client.streamSSE(t.Context(), "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+
+	if !iter.Next() {
+		t.Fatal("expected event")
+	}
+	ev := iter.Event()
+	if !ev.IsProgress() {
+		t.Fatal("expected progress event")
+	}
+	if ev.NodeID != "n1" || *ev.ProgressPercent != 42 {
+		t.Errorf("expected n1 at 42%%, got %s at %d%%", ev.NodeID, *ev.ProgressPercent)
+	}
+}
+
 func TestSSEIterInvalidJSON(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/event-stream")
@@ -186,3 +252,517 @@ func TestSSEIterStreamEnd(t *testing.T) {
 		t.Errorf("unexpected error: %v", iter.Err())
 	}
 }
+
+func TestSSEIterContextCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintln(w, "data: keepalive")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done() // hang until the client goes away
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	client := NewClient("key", WithBaseURL(srv.URL))
+	iter, err := client.streamSSE(ctx, "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+
+	if !iter.Next() {
+		t.Fatal("expected first event")
+	}
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	if iter.Next() {
+		t.Fatal("expected Next to return false after cancel")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Next took %s to observe cancellation", elapsed)
+	}
+
+	var streamErr *StreamError
+	if !errors.As(iter.Err(), &streamErr) || !errors.Is(iter.Err(), context.Canceled) {
+		t.Errorf("expected StreamError wrapping context.Canceled, got %v", iter.Err())
+	}
+}
+
+func TestSSEClientReused(t *testing.T) {
+	client := NewClient("key", WithTimeout(time.Second))
+	if client.sseClient == nil {
+		t.Fatal("expected SSE client to be created in NewClient")
+	}
+	if client.sseClient.Timeout != 0 {
+		t.Errorf("expected no overall timeout on SSE client, got %s", client.sseClient.Timeout)
+	}
+	tr, ok := client.sseClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", client.sseClient.Transport)
+	}
+	if tr.ResponseHeaderTimeout != DefaultStreamHeaderTimeout {
+		t.Errorf("expected header timeout %s, got %s", DefaultStreamHeaderTimeout, tr.ResponseHeaderTimeout)
+	}
+}
+
+func TestSSEStreamConnectTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-release // never send headers
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		time.Sleep(150 * time.Millisecond) // slower than the connect timeout
+		fmt.Fprintln(w, "data: keepalive")
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := NewClient("key", WithBaseURL(srv.URL), WithStreamConnectTimeout(50*time.Millisecond))
+
+	start := time.Now()
+	_, err := client.streamSSE(t.Context(), "/slow")
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Fatalf("expected ConnectionError, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("connect took %s despite timeout", elapsed)
+	}
+
+	iter, err := client.streamSSE(t.Context(), "/fast-headers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+	if !iter.Next() {
+		t.Fatalf("expected event after connect timeout elapsed, got err %v", iter.Err())
+	}
+}
+
+func TestWorkflowsWaitForStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/workflow-requests/req-1/listen" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintln(w, "data: keepalive")
+		fmt.Fprintln(w, `data: {"workflow_request":{"id":"req-1","status":"pending"}}`)
+		fmt.Fprintln(w, `data: {"workflow_request":{"id":"req-1","status":"in_progress"}}`)
+		fmt.Fprintln(w, `data: {"workflow_request":{"id":"req-1","status":"completed"}}`)
+	}))
+	defer srv.Close()
+	client := NewClient("key", WithBaseURL(srv.URL))
+
+	req, err := client.Workflows.WaitForStatus(t.Context(), "req-1", StatusInProgress, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Status != "in_progress" {
+		t.Errorf("expected in_progress, got %s", req.Status)
+	}
+
+	// A terminal status ends the wait even if it isn't the target.
+	req, err = client.Workflows.WaitForStatus(t.Context(), "req-1", "awaiting_input", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Status != "completed" {
+		t.Errorf("expected completed, got %s", req.Status)
+	}
+}
+
+func TestWorkflowsWaitForStatusTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintln(w, `data: {"workflow_request":{"id":"req-1","status":"in_progress"}}`)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	client := NewClient("key", WithBaseURL(srv.URL))
+
+	_, err := client.Workflows.WaitForStatus(t.Context(), "req-1", StatusCompleted, 50*time.Millisecond)
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Errorf("expected TimeoutError, got %v", err)
+	}
+}
+
+func TestWaitReturnsOnErrorEvent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/workflow-requests/run" {
+			fmt.Fprint(w, `{"workflow_request_id":"req-1"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintln(w, `data: {"type":"error","error":"model overloaded"}`)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done() // never send a terminal status
+	}))
+	defer srv.Close()
+	client := NewClient("key", WithBaseURL(srv.URL))
+
+	start := time.Now()
+	_, err := client.Workflows.RunAndWait(t.Context(), RunParams{WorkflowVersionID: "ver-1"}, 5*time.Second)
+	var execErr *ExecutionError
+	if !errors.As(err, &execErr) || execErr.Message != "model overloaded" || execErr.WorkflowRequestID != "req-1" {
+		t.Errorf("RunAndWait: expected ExecutionError, got %v", err)
+	}
+
+	_, err = client.Workflows.WaitForStatus(t.Context(), "req-1", StatusCompleted, 5*time.Second)
+	if !errors.As(err, &execErr) {
+		t.Errorf("WaitForStatus: expected ExecutionError, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("waits took %s; expected to return promptly", elapsed)
+	}
+}
+
+func TestRunAndWaitErrorOnFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/workflow-requests/run":
+			fmt.Fprint(w, `{"workflow_request_id":"req-1"}`)
+		case "/workflow-requests/req-1/execution-tree":
+			fmt.Fprint(w, `{"execution_tree":{"workflow_request_id":"req-1","status":"failed","nodes":[
+				{"id":"en-1","node_label":"Start","status":"completed"},
+				{"id":"en-2","node_label":"Agent","status":"failed","output_data":{"error":"tool timed out"}}]}}`)
+		default:
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintln(w, `data: {"workflow_request":{"id":"req-1","status":"failed"}}`)
+		}
+	}))
+	defer srv.Close()
+	client := NewClient("key", WithBaseURL(srv.URL))
+	params := RunParams{WorkflowVersionID: "ver-1"}
+
+	tree, err := client.Workflows.RunAndWait(t.Context(), params, 5*time.Second)
+	if err != nil || tree == nil {
+		t.Fatalf("expected tree and no error by default, got %v, %v", tree, err)
+	}
+
+	tree, err = client.Workflows.RunAndWait(t.Context(), params, 5*time.Second, WithErrorOnFailure())
+	var execErr *ExecutionError
+	if !errors.As(err, &execErr) {
+		t.Fatalf("expected ExecutionError, got %v", err)
+	}
+	if execErr.NodeLabel != "Agent" || execErr.Message != "tool timed out" || execErr.Output["error"] != "tool timed out" {
+		t.Errorf("unexpected error: %+v", execErr)
+	}
+	if tree == nil || tree.ExecutionTree.Status != "failed" {
+		t.Errorf("expected the tree alongside the error, got %+v", tree)
+	}
+}
+
+func TestRunAndWaitProgress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/workflow-versions/ver-1":
+			fmt.Fprint(w, `{"nodes":[{"id":"n-1","label":"Start"},{"id":"n-2","label":"Agent"},{"id":"n-3","label":"Output"}]}`)
+		case "/workflow-requests/run":
+			fmt.Fprint(w, `{"workflow_request_id":"req-1"}`)
+		case "/workflow-requests/req-1/execution-tree":
+			fmt.Fprint(w, `{"execution_tree":{"workflow_request_id":"req-1","status":"completed"}}`)
+		default:
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, ": keepalive\n\n")
+			fmt.Fprintln(w, `data: {"node_execution":{"node_id":"n-1","status":"in_progress"}}`)
+			fmt.Fprintln(w, `data: {"node_execution":{"node_id":"n-1","status":"completed"}}`)
+			fmt.Fprintln(w, `data: {"node_execution":{"node_id":"n-2","status":"in_progress"}}`)
+			fmt.Fprintln(w, `data: {"type":"text_delta","delta":"Hel"}`)
+			fmt.Fprintln(w, `data: {"type":"text_delta","delta":"lo"}`)
+			fmt.Fprintln(w, `data: {"node_execution":{"node_id":"n-2","status":"in_progress"}}`)
+			fmt.Fprintln(w, `data: {"node_execution":{"node_id":"n-2","status":"completed"}}`)
+			fmt.Fprintln(w, `data: {"workflow_request":{"id":"req-1","status":"completed"}}`)
+		}
+	}))
+	defer srv.Close()
+	client := NewClient("key", WithBaseURL(srv.URL))
+
+	var got []Progress
+	tree, err := client.Workflows.RunAndWaitProgress(t.Context(), RunParams{WorkflowVersionID: "ver-1"}, 5*time.Second, func(p Progress) {
+		got = append(got, p)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.ExecutionTree.Status != "completed" {
+		t.Errorf("expected completed tree, got %+v", tree)
+	}
+	want := []Progress{
+		{CompletedNodes: 0, TotalNodes: 3, CurrentNodeLabel: "Start"},
+		{CompletedNodes: 1, TotalNodes: 3, CurrentNodeLabel: "Start"},
+		{CompletedNodes: 1, TotalNodes: 3, CurrentNodeLabel: "Agent"},
+		{CompletedNodes: 2, TotalNodes: 3, CurrentNodeLabel: "Agent"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected progress %+v, got %+v", want, got)
+	}
+
+	var valErr *ValidationError
+	if _, err := client.Workflows.RunAndWaitProgress(t.Context(), RunParams{WorkflowVersionID: "ver-1"}, 5*time.Second, nil); !errors.As(err, &valErr) {
+		t.Errorf("expected ValidationError for a nil callback, got %v", err)
+	}
+}
+
+func TestStreamRaw(t *testing.T) {
+	const stream = "event: custom\ndata: {\"x\":1}\n\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+		if r.Header.Get("Accept") != "text/event-stream" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, stream)
+	}))
+	defer srv.Close()
+	client := NewClient("key", WithBaseURL(srv.URL))
+
+	body, err := client.StreamRaw(t.Context(), "/workflow-requests/req-1/listen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := body.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != stream {
+		t.Errorf("expected raw stream %q, got %q", stream, raw)
+	}
+
+	var notFound *NotFoundError
+	if _, err := client.StreamRaw(t.Context(), "/missing"); !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestSSEIterRetryDirective(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: keepalive\n\n")
+		fmt.Fprint(w, "retry: 2500\ndata: keepalive\n\n")
+		fmt.Fprint(w, "retry: soon\nretry: -1\ndata: keepalive\n\n")
+	}))
+	defer srv.Close()
+	client := NewClient("key", WithBaseURL(srv.URL))
+
+	iter, err := client.streamSSE(t.Context(), "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+
+	iter.Next()
+	if _, ok := iter.RetryDelay(); ok {
+		t.Error("expected no retry delay before the server sends one")
+	}
+	iter.Next()
+	if d, ok := iter.RetryDelay(); !ok || d != 2500*time.Millisecond {
+		t.Errorf("expected 2.5s, got %s (ok=%v)", d, ok)
+	}
+	iter.Next()
+	if d, _ := iter.RetryDelay(); d != 2500*time.Millisecond {
+		t.Errorf("expected malformed values to be ignored, got %s", d)
+	}
+}
+
+func TestWorkflowsListenFrom(t *testing.T) {
+	var gotLastID []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotLastID = append(gotLastID, r.Header.Get("Last-Event-ID"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "id: 7\ndata: {\"workflow_request\":{\"id\":\"req-1\",\"status\":\"in_progress\"}}\n\n")
+		fmt.Fprint(w, "data: keepalive\n\n")
+	}))
+	defer srv.Close()
+	client := NewClient("key", WithBaseURL(srv.URL))
+
+	iter, err := client.Workflows.ListenFrom(t.Context(), "req-1", "6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+	if !iter.Next() {
+		t.Fatalf("expected event, got err %v", iter.Err())
+	}
+	if iter.Event().ID != "7" {
+		t.Errorf("expected event ID 7, got %q", iter.Event().ID)
+	}
+	for iter.Next() {
+	}
+	if iter.LastEventID() != "7" {
+		t.Errorf("expected last event ID 7, got %q", iter.LastEventID())
+	}
+
+	fresh, err := client.Workflows.ListenFrom(t.Context(), "req-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh.Close()
+
+	if len(gotLastID) != 2 || gotLastID[0] != "6" || gotLastID[1] != "" {
+		t.Errorf("unexpected Last-Event-ID headers: %q", gotLastID)
+	}
+}
+
+func TestCollectToolCalls(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, line := range []string{
+			`{"type":"text_delta","delta":"Let me check."}`,
+			`{"type":"tool_call_start","tool_call_id":"tc-1","tool_name":"search"}`,
+			`{"type":"tool_call_delta","tool_call_id":"tc-1","tool_args_delta":"{\"query\":"}`,
+			`{"type":"tool_call_start","tool_call_id":"tc-2","tool_name":"delete"}`,
+			`{"type":"tool_call_delta","tool_call_id":"tc-1","tool_args_delta":"\"go sdk\"}"}`,
+			`{"type":"tool_approval_request","tool_call_id":"tc-2","tool_name":"delete","args":{"id":"x"}}`,
+			`{"type":"tool_approval_response","tool_call_id":"tc-2","approved":false}`,
+			`{"type":"tool_complete","tool_call_id":"tc-1","tool_name":"search","result":{"hits":3}}`,
+			`{"type":"tool_error","tool_call_id":"tc-2","tool_name":"delete","error":"not approved"}`,
+			`{"workflow_request":{"id":"req-1","status":"completed"}}`,
+			`{"type":"tool_call_start","tool_call_id":"tc-late","tool_name":"ignored"}`,
+		} {
+			fmt.Fprintln(w, "data: "+line)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient("key", WithBaseURL(srv.URL))
+	iter, err := client.streamSSE(t.Context(), "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+
+	calls, err := CollectToolCalls(iter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %+v", calls)
+	}
+	search, del := calls[0], calls[1]
+	if search.ID != "tc-1" || search.Name != "search" || search.Args["query"] != "go sdk" {
+		t.Errorf("unexpected search call: %+v", search)
+	}
+	if res, _ := search.Result.(map[string]any); res["hits"] != float64(3) {
+		t.Errorf("unexpected search result: %v", search.Result)
+	}
+	if del.Args["id"] != "x" || del.Approved == nil || *del.Approved || del.Error != "not approved" {
+		t.Errorf("unexpected delete call: %+v", del)
+	}
+}
+
+func TestCollectToolCallsBadArgs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintln(w, `data: {"type":"tool_call_delta","tool_call_id":"tc-1","tool_name":"search","tool_args_delta":"{\"query\":"}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient("key", WithBaseURL(srv.URL))
+	iter, err := client.streamSSE(t.Context(), "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+
+	calls, err := CollectToolCalls(iter)
+	if err == nil {
+		t.Error("expected parse error for truncated args")
+	}
+	if len(calls) != 1 || calls[0].Name != "search" {
+		t.Errorf("expected partial call to be returned, got %+v", calls)
+	}
+}
+
+func TestChatsFollow(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch r.URL.Path {
+		case "/chat-internal-messages/chat-1/listen":
+			fmt.Fprintln(w, `data: {"type":"text_delta","delta":"hi","run_id":"run-chat"}`)
+			fmt.Fprintln(w, `data: {"workflow_request":{"id":"req-1","status":"in_progress"}}`)
+			fmt.Fprintln(w, `data: {"workflow_request":{"id":"req-1","status":"in_progress"}}`)
+		case "/workflow-requests/req-1/listen":
+			fmt.Fprintln(w, `data: {"type":"tool_call_start","tool_call_id":"tc-1","tool_name":"search"}`)
+			fmt.Fprintln(w, `data: {"workflow_request":{"id":"req-1","status":"completed"}}`)
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	client := NewClient("key", WithBaseURL(srv.URL))
+
+	iter, err := client.Chats.Follow(t.Context(), "chat-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+
+	var events []SSEEvent
+	for iter.Next() {
+		events = append(events, iter.Event())
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 5 {
+		t.Fatalf("expected 5 merged events, got %d: %+v", len(events), events)
+	}
+	var sawTool bool
+	for _, ev := range events {
+		if ev.EventType == "tool_call_start" {
+			sawTool = true
+			if ev.RunID != "req-1" {
+				t.Errorf("expected request event annotated with req-1, got %q", ev.RunID)
+			}
+		}
+		if ev.EventType == "text_delta" && ev.RunID != "run-chat" {
+			t.Errorf("chat event RunID should be kept, got %q", ev.RunID)
+		}
+	}
+	if !sawTool {
+		t.Error("expected events from the request stream")
+	}
+}
+
+func TestChatsFollowClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintln(w, `data: {"type":"text_delta","delta":"hi"}`)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	client := NewClient("key", WithBaseURL(srv.URL))
+
+	iter, err := client.Chats.Follow(t.Context(), "chat-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !iter.Next() {
+		t.Fatalf("expected event, got %v", iter.Err())
+	}
+
+	done := make(chan struct{})
+	go func() {
+		iter.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not return")
+	}
+}
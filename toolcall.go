@@ -0,0 +1,99 @@
+package splox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ToolCall is a tool invocation reassembled from stream events by
+// [CollectToolCalls].
+type ToolCall struct {
+	ID       string
+	Name     string
+	Args     map[string]any
+	Result   any
+	Error    string
+	Approved *bool // set if the call went through tool approval
+}
+
+// CollectToolCalls reads iter until the stream ends, the workflow request
+// reaches a terminal status, or a "stopped" event arrives, and returns the
+// tool calls seen, in the order they started. Args are built by joining the
+// "tool_call_delta" chunks and parsing the result as JSON; if no deltas were
+// streamed, the args of a "tool_approval_request" are used instead.
+//
+// A server-sent "error" event ends collection with an [*ExecutionError].
+// Calls collected so far are returned alongside any error.
+func CollectToolCalls(iter *SSEIter) ([]ToolCall, error) {
+	var (
+		order []string
+		calls = map[string]*ToolCall{}
+		args  = map[string][]byte{}
+	)
+	get := func(ev SSEEvent) *ToolCall {
+		tc, ok := calls[ev.ToolCallID]
+		if !ok {
+			tc = &ToolCall{ID: ev.ToolCallID}
+			calls[ev.ToolCallID] = tc
+			order = append(order, ev.ToolCallID)
+		}
+		if tc.Name == "" {
+			tc.Name = ev.ToolName
+		}
+		return tc
+	}
+
+	var streamErr error
+loop:
+	for iter.Next() {
+		ev := iter.Event()
+		if ev.IsError() {
+			streamErr = &ExecutionError{Message: ev.errorMessage()}
+			break
+		}
+		if ev.WorkflowRequest != nil && Status(ev.WorkflowRequest.Status).IsTerminal() {
+			break
+		}
+
+		switch ev.EventType {
+		case "stopped":
+			break loop
+		case "tool_call_start", "tool_start":
+			get(ev)
+		case "tool_call_delta":
+			get(ev)
+			args[ev.ToolCallID] = append(args[ev.ToolCallID], ev.ToolArgsDelta...)
+		case "tool_complete":
+			get(ev).Result = ev.ToolResult
+		case "tool_error":
+			get(ev).Error = ev.Error
+		case "tool_approval_request":
+			tc := get(ev)
+			if m, ok := ev.ToolArgs.(map[string]any); ok && tc.Args == nil {
+				tc.Args = m
+			}
+		case "tool_approval_response":
+			get(ev).Approved = ev.Approved
+		}
+	}
+	if streamErr == nil {
+		streamErr = iter.Err()
+	}
+
+	errs := []error{streamErr}
+	result := make([]ToolCall, 0, len(order))
+	for _, id := range order {
+		tc := calls[id]
+		if raw := args[id]; len(raw) > 0 {
+			var m map[string]any
+			if err := json.Unmarshal(raw, &m); err != nil {
+				errs = append(errs, fmt.Errorf("splox: tool call %s: parse args: %w", id, err))
+			} else {
+				tc.Args = m
+			}
+		}
+		result = append(result, *tc)
+	}
+	return result, errors.Join(errs...)
+}
@@ -0,0 +1,57 @@
+package splox
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// Tracer starts spans around API calls. It is a thin interface so the SDK
+// stays free of tracing dependencies; an OpenTelemetry trace.Tracer can be
+// adapted in a few lines (see the README).
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation started by a [Tracer].
+type Span interface {
+	SetAttribute(key string, value any)
+	RecordError(err error)
+	End()
+}
+
+// Span attribute keys set by the SDK.
+const (
+	AttrHTTPMethod     = "http.request.method"
+	AttrHTTPStatusCode = "http.response.status_code"
+	AttrRequestID      = "splox.request_id"
+)
+
+// requestIDHeader is the response header carrying the server's request ID.
+const requestIDHeader = "X-Request-ID"
+
+// startSpan starts a span for an API call if a tracer is configured.
+// The returned span is nil when tracing is disabled.
+func (c *Client) startSpan(ctx context.Context, method, path string) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, nil
+	}
+	ctx, span := c.tracer.Start(ctx, "splox."+method+" "+pathTemplate(path))
+	span.SetAttribute(AttrHTTPMethod, method)
+	return ctx, span
+}
+
+// pathTemplate replaces ID-like path segments with "{id}" so span names stay
+// low-cardinality. A segment is treated as an ID if it contains a digit.
+func pathTemplate(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if strings.IndexFunc(seg, unicode.IsDigit) >= 0 {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
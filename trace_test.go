@@ -0,0 +1,96 @@
+package splox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeSpan struct {
+	name  string
+	attrs map[string]any
+	errs  []error
+	ended bool
+}
+
+func (s *fakeSpan) SetAttribute(key string, value any) { s.attrs[key] = value }
+func (s *fakeSpan) RecordError(err error)              { s.errs = append(s.errs, err) }
+func (s *fakeSpan) End()                               { s.ended = true }
+
+type fakeTracer struct{ spans []*fakeSpan }
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	s := &fakeSpan{name: name, attrs: map[string]any{}}
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+func TestTracerSpans(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "rid-1")
+		switch r.URL.Path {
+		case "/chats/chat-001":
+			fmt.Fprint(w, `{"id":"chat-001"}`)
+		case "/chat-internal-messages/chat-001/listen":
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintln(w, "data: keepalive")
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer srv.Close()
+
+	tracer := &fakeTracer{}
+	client := NewClient("key", WithBaseURL(srv.URL), WithTracer(tracer))
+
+	if _, err := client.Chats.Get(t.Context(), "chat-001"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Chats.Get(t.Context(), "missing-1"); err == nil {
+		t.Fatal("expected error")
+	}
+	iter, err := client.Chats.Listen(t.Context(), "chat-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tracer.spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(tracer.spans))
+	}
+	ok := tracer.spans[0]
+	if ok.name != "splox.GET /chats/{id}" {
+		t.Errorf("unexpected span name %q", ok.name)
+	}
+	if ok.attrs[AttrHTTPStatusCode] != 200 || ok.attrs[AttrRequestID] != "rid-1" || !ok.ended {
+		t.Errorf("unexpected span: %+v", ok)
+	}
+	var notFound *NotFoundError
+	if failed := tracer.spans[1]; len(failed.errs) != 1 || !errors.As(failed.errs[0], &notFound) {
+		t.Errorf("expected NotFoundError recorded, got %v", failed.errs)
+	}
+
+	stream := tracer.spans[2]
+	if stream.ended {
+		t.Error("stream span should stay open until Close")
+	}
+	iter.Close()
+	if !stream.ended {
+		t.Error("expected stream span to end on Close")
+	}
+}
+
+func TestPathTemplate(t *testing.T) {
+	cases := map[string]string{
+		"/workflows/wf-001/versions/latest": "/workflows/{id}/versions/latest",
+		"/billing/transactions?page=2":      "/billing/transactions",
+		"/chats/api/3f2a9c1e":               "/chats/api/{id}",
+	}
+	for in, want := range cases {
+		if got := pathTemplate(in); got != want {
+			t.Errorf("pathTemplate(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -1,60 +1,136 @@
 package splox
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
-// do executes an HTTP request and decodes the JSON response into dst.
-// If dst is nil the response body is discarded (useful for DELETE/204).
-func (c *Client) do(ctx context.Context, method, path string, body any, dst any) error {
-	u := c.baseURL + path
+// newDefaultTransport returns the transport used when the caller doesn't
+// supply one: net/http's defaults tuned for many requests to a single host.
+func newDefaultTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ForceAttemptHTTP2 = true
+	t.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	t.IdleConnTimeout = DefaultIdleConnTimeout
+	return t
+}
 
-	var bodyReader io.Reader
-	if body != nil {
-		b, err := json.Marshal(body)
-		if err != nil {
-			return fmt.Errorf("splox: marshal request body: %w", err)
+// RequestOption customizes a single API call.
+type RequestOption func(*requestConfig)
+
+// requestConfig collects per-request settings from RequestOptions.
+type requestConfig struct {
+	headers map[string]string
+	meta    *ResultMeta
+}
+
+// WithHeader sets a header on a single request. It overrides both the SDK's
+// built-in headers and those from [WithDefaultHeaders].
+func WithHeader(key, value string) RequestOption {
+	return func(rc *requestConfig) {
+		if rc.headers == nil {
+			rc.headers = make(map[string]string)
 		}
-		bodyReader = bytes.NewReader(b)
+		rc.headers[key] = value
 	}
+}
 
-	req, err := http.NewRequestWithContext(ctx, method, u, bodyReader)
-	if err != nil {
-		return fmt.Errorf("splox: create request: %w", err)
-	}
+// ResultMeta describes the HTTP response of a call made with [WithResultMeta].
+type ResultMeta struct {
+	StatusCode int
+	Headers    http.Header
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	}
+// WithResultMeta fills meta with the response status code and headers once
+// the call returns, e.g. to tell 200 from 201 on a create. meta is filled
+// whenever a response is received, including error responses.
+func WithResultMeta(meta *ResultMeta) RequestOption {
+	return func(rc *requestConfig) { rc.meta = meta }
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return &ConnectionError{Err: err}
+// do executes an HTTP request and decodes the JSON response into dst.
+// If dst is nil the response body is discarded (useful for DELETE/204).
+func (c *Client) do(ctx context.Context, method, path string, body any, dst any, opts ...RequestOption) error {
+	var rc requestConfig
+	for _, opt := range opts {
+		opt(&rc)
 	}
-	defer resp.Body.Close()
+	return c.doWithHeaders(ctx, method, c.url(path), body, dst, rc.headers, rc.meta)
+}
 
-	if err := checkStatus(resp); err != nil {
-		return err
+// setCommonHeaders sets the headers shared by every API request. The
+// credential provider, if any, is called once per request.
+func (c *Client) setCommonHeaders(ctx context.Context, req *http.Request) error {
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
 	}
+	key := c.apiKey
+	if c.credentials != nil {
+		var err error
+		if key, err = c.credentials(ctx); err != nil {
+			return fmt.Errorf("splox: get credentials: %w", err)
+		}
+	}
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	return nil
+}
 
-	if dst == nil || resp.StatusCode == http.StatusNoContent {
+// intercept applies the request interceptor, if any, to req.
+func (c *Client) intercept(req *http.Request) error {
+	if c.interceptor == nil {
 		return nil
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
-		return fmt.Errorf("splox: decode response: %w", err)
+	if err := c.interceptor(req); err != nil {
+		return fmt.Errorf("splox: request interceptor: %w", err)
 	}
 	return nil
 }
 
+// bodyDecoder can be passed as dst to consume the response body directly,
+// e.g. to decode it incrementally.
+type bodyDecoder func(r io.Reader) error
+
+// newDecoder returns a JSON decoder that honors [WithUseJSONNumber].
+func (c *Client) newDecoder(r io.Reader) *json.Decoder {
+	dec := json.NewDecoder(r)
+	if c.useJSONNumber {
+		dec.UseNumber()
+	}
+	return dec
+}
+
+// compressionThreshold is the minimum request body size, in bytes, that is
+// gzipped when [WithRequestCompression] is enabled.
+const compressionThreshold = 1024
+
+// gzipBytes returns b gzip-compressed.
+func gzipBytes(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // addParams appends query parameters to a path.
 func addParams(path string, params url.Values) string {
 	if len(params) == 0 {
@@ -63,17 +139,47 @@ func addParams(path string, params url.Values) string {
 	return path + "?" + params.Encode()
 }
 
-// doWithHeaders is like do but allows adding extra request headers.
-func (c *Client) doWithHeaders(ctx context.Context, method, fullURL string, body any, dst any, headers map[string]string) error {
+// doWithHeaders is like do but allows adding extra request headers. meta may
+// be nil.
+func (c *Client) doWithHeaders(ctx context.Context, method, fullURL string, body any, dst any, headers map[string]string, meta *ResultMeta) (err error) {
+	if _, ok := ctx.Deadline(); !ok && c.defaultTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.defaultTimeout)
+		defer cancel()
+	}
+	if c.breaker != nil {
+		if err := c.breaker.allow(); err != nil {
+			return err
+		}
+		defer func() { c.breaker.record(err) }()
+	}
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return err
+		}
+	}
+
 	var bodyReader io.Reader
+	compressed := false
 	if body != nil {
 		b, err := json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("splox: marshal request body: %w", err)
 		}
+		if c.compressRequests && len(b) > compressionThreshold {
+			if b, err = gzipBytes(b); err != nil {
+				return fmt.Errorf("splox: compress request body: %w", err)
+			}
+			compressed = true
+		}
 		bodyReader = bytes.NewReader(b)
 	}
 
+	ctx, span := c.startSpan(ctx, method, strings.TrimPrefix(fullURL, c.url("")))
+	if span != nil {
+		defer span.End()
+	}
+
 	req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
 	if err != nil {
 		return fmt.Errorf("splox: create request: %w", err)
@@ -81,29 +187,129 @@ func (c *Client) doWithHeaders(ctx context.Context, method, fullURL string, body
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
-	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if err := c.setCommonHeaders(ctx, req); err != nil {
+		return err
 	}
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
 
+	path := req.URL.Path
+	if c.logger != nil {
+		c.logger.LogRequest(ctx, method, path)
+	}
+	start := time.Now()
+	status, err := c.send(req, dst, span, meta)
+	if c.logger != nil {
+		c.logger.LogResponse(ctx, method, path, status, time.Since(start), err)
+	}
+	if span != nil && err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+// send performs req and decodes the response into dst. It returns the HTTP
+// status code, or 0 if no response was received. span and meta may be nil.
+func (c *Client) send(req *http.Request, dst any, span Span, meta *ResultMeta) (int, error) {
+	if err := c.intercept(req); err != nil {
+		return 0, err
+	}
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return &ConnectionError{Err: err}
+		return 0, &ConnectionError{Err: redactURLError(err)}
 	}
 	defer resp.Body.Close()
 
+	if meta != nil {
+		meta.StatusCode = resp.StatusCode
+		meta.Headers = resp.Header
+	}
+
+	if span != nil {
+		span.SetAttribute(AttrHTTPStatusCode, resp.StatusCode)
+		if id := resp.Header.Get(requestIDHeader); id != "" {
+			span.SetAttribute(AttrRequestID, id)
+		}
+	}
+
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return resp.StatusCode, fmt.Errorf("splox: decompress response: %w", err)
+		}
+		defer zr.Close()
+		resp.Body = zr
+	}
+	// Incremental decoders exist to handle bodies too large to buffer.
+	if _, incremental := dst.(bodyDecoder); c.maxResponseBytes > 0 && !incremental {
+		resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: c.maxResponseBytes, limit: c.maxResponseBytes}
+	}
+
+	if c.responseTap != nil {
+		raw, err := io.ReadAll(resp.Body)
+		if err != nil {
+			var tooLarge *ResponseTooLargeError
+			if errors.As(err, &tooLarge) {
+				return resp.StatusCode, tooLarge
+			}
+			return resp.StatusCode, &ConnectionError{Err: err}
+		}
+		c.responseTap(req.Method, req.URL.Path, resp.StatusCode, bytes.Clone(raw))
+		resp.Body = io.NopCloser(bytes.NewReader(raw))
+	}
+
 	if err := checkStatus(resp); err != nil {
-		return err
+		return resp.StatusCode, err
 	}
 
 	if dst == nil || resp.StatusCode == http.StatusNoContent {
-		return nil
+		return resp.StatusCode, nil
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
-		return fmt.Errorf("splox: decode response: %w", err)
+	if decode, ok := dst.(bodyDecoder); ok {
+		return resp.StatusCode, decode(resp.Body)
 	}
-	return nil
+
+	// Some endpoints answer 200 with an empty body; treat that as success
+	// and leave dst zero-valued.
+	br := bufio.NewReader(resp.Body)
+	if _, err := br.Peek(1); err == io.EOF {
+		return resp.StatusCode, nil
+	}
+	if err := c.newDecoder(br).Decode(dst); err != nil {
+		var tooLarge *ResponseTooLargeError
+		if errors.As(err, &tooLarge) {
+			return resp.StatusCode, tooLarge
+		}
+		return resp.StatusCode, fmt.Errorf("splox: decode response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// limitedBody fails reads with a [*ResponseTooLargeError] once more than
+// limit bytes have been read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, &ResponseTooLargeError{Limit: b.limit}
+	}
+	// Read at most one byte past the limit to detect overflow.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n - 1, &ResponseTooLargeError{Limit: b.limit}
+	}
+	return n, err
 }
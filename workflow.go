@@ -2,9 +2,17 @@ package splox
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 )
 
 // WorkflowService provides methods for the Workflows API.
@@ -14,17 +22,22 @@ type WorkflowService struct {
 
 // ListParams are optional parameters for [WorkflowService.List].
 type ListParams struct {
-	Limit  int
+	Limit  int // 1-100; larger values are capped
 	Cursor string
 	Search string
+
+	// Status filters by version status, e.g. "draft" or "published".
+	Status string
+	// IsPublic, when set, filters by workflow visibility.
+	IsPublic *bool
 }
 
 // List returns the authenticated user's workflows.
 func (s *WorkflowService) List(ctx context.Context, params *ListParams) (*WorkflowListResponse, error) {
 	v := url.Values{}
 	if params != nil {
-		if params.Limit > 0 {
-			v.Set("limit", fmt.Sprintf("%d", params.Limit))
+		if err := setLimit(v, params.Limit); err != nil {
+			return nil, err
 		}
 		if params.Cursor != "" {
 			v.Set("cursor", params.Cursor)
@@ -32,6 +45,12 @@ func (s *WorkflowService) List(ctx context.Context, params *ListParams) (*Workfl
 		if params.Search != "" {
 			v.Set("search", params.Search)
 		}
+		if params.Status != "" {
+			v.Set("status", params.Status)
+		}
+		if params.IsPublic != nil {
+			v.Set("is_public", strconv.FormatBool(*params.IsPublic))
+		}
 	}
 
 	var resp WorkflowListResponse
@@ -59,10 +78,39 @@ func (s *WorkflowService) GetLatestVersion(ctx context.Context, workflowID strin
 	return &resp, nil
 }
 
-// ListVersions returns all versions of a workflow.
-func (s *WorkflowService) ListVersions(ctx context.Context, workflowID string) (*WorkflowVersionListResponse, error) {
+// GetVersion returns a specific workflow version with its nodes and edges,
+// e.g. to inspect or run against a pinned older version.
+func (s *WorkflowService) GetVersion(ctx context.Context, workflowVersionID string) (*WorkflowFullResponse, error) {
+	var resp WorkflowFullResponse
+	if err := s.client.do(ctx, "GET", "/workflow-versions/"+workflowVersionID, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListVersionsParams are optional parameters for [WorkflowService.ListVersions].
+type ListVersionsParams struct {
+	Status string // e.g. "draft" or "published"
+	Order  string // by version number: "asc" or "desc"
+}
+
+// ListVersions returns the versions of a workflow. Without params it lists
+// all versions in the server's default order; only the first params value is
+// used.
+func (s *WorkflowService) ListVersions(ctx context.Context, workflowID string, params ...*ListVersionsParams) (*WorkflowVersionListResponse, error) {
+	v := url.Values{}
+	if len(params) > 0 && params[0] != nil {
+		params := params[0]
+		if params.Status != "" {
+			v.Set("status", params.Status)
+		}
+		if params.Order != "" {
+			v.Set("order", params.Order)
+		}
+	}
+
 	var resp WorkflowVersionListResponse
-	if err := s.client.do(ctx, "GET", "/workflows/"+workflowID+"/versions", nil, &resp); err != nil {
+	if err := s.client.do(ctx, "GET", addParams("/workflows/"+workflowID+"/versions", v), nil, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -77,6 +125,61 @@ func (s *WorkflowService) GetEntryNodes(ctx context.Context, workflowVersionID s
 	return &resp, nil
 }
 
+// WorkflowBundleSchemaVersion is the bundle format version written by
+// [WorkflowService.Export]. Version 1 bundles held only metadata for
+// non-draft versions.
+const WorkflowBundleSchemaVersion = 2
+
+// WorkflowBundle is a portable, self-contained workflow export.
+type WorkflowBundle struct {
+	SchemaVersion int                    `json:"schema_version"`
+	Workflow      WorkflowFullResponse   `json:"workflow"` // draft version with its nodes and edges
+	Versions      []WorkflowFullResponse `json:"versions"` // every version with its nodes and edges
+}
+
+// Export fetches a workflow's draft and every version, each with its nodes
+// and edges, and serializes them into a [WorkflowBundle] for backup or
+// migration. It makes one request per version.
+func (s *WorkflowService) Export(ctx context.Context, workflowID string) ([]byte, error) {
+	full, err := s.Get(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.ListVersions(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reuse the canonical encoding so bundles diff cleanly.
+	canonical, err := full.MarshalCanonical()
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]json.RawMessage, len(list.Versions))
+	for i, v := range list.Versions {
+		version := full
+		if v.ID != full.WorkflowVersion.ID {
+			if version, err = s.GetVersion(ctx, v.ID); err != nil {
+				return nil, fmt.Errorf("splox: export version %s: %w", v.ID, err)
+			}
+		}
+		if versions[i], err = version.MarshalCanonical(); err != nil {
+			return nil, err
+		}
+	}
+	bundle := struct {
+		SchemaVersion int               `json:"schema_version"`
+		Workflow      json.RawMessage   `json:"workflow"`
+		Versions      []json.RawMessage `json:"versions"`
+	}{WorkflowBundleSchemaVersion, canonical, versions}
+
+	b, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("splox: marshal workflow bundle: %w", err)
+	}
+	return b, nil
+}
+
 // RunParams are the parameters for [WorkflowService.Run].
 type RunParams struct {
 	WorkflowVersionID string                `json:"workflow_version_id"`
@@ -85,32 +188,358 @@ type RunParams struct {
 	Query             string                `json:"query"`
 	Files             []WorkflowRequestFile `json:"files,omitempty"`
 	AdditionalParams  map[string]any        `json:"additional_params,omitempty"`
+	Metadata          map[string]any        `json:"metadata,omitempty"` // Stored on the workflow request; searchable via SearchRequests
+}
+
+// Redacted returns a copy of p that is safe to log: Query is replaced by its
+// length, AdditionalParams and Metadata keep their keys but every value is
+// replaced, and Files keeps each file's name, type, and size but drops its
+// URL and metadata values. IDs are kept.
+func (p RunParams) Redacted() RunParams {
+	if p.Query != "" {
+		p.Query = fmt.Sprintf("%s (%d chars)", redactedText, utf8.RuneCountInString(p.Query))
+	}
+	p.AdditionalParams = redactValues(p.AdditionalParams)
+	p.Metadata = redactValues(p.Metadata)
+	if p.Files != nil {
+		files := make([]WorkflowRequestFile, len(p.Files))
+		for i, f := range p.Files {
+			if f.URL != "" {
+				f.URL = redactedText
+			}
+			f.Metadata = redactValues(f.Metadata)
+			files[i] = f
+		}
+		p.Files = files
+	}
+	return p
+}
+
+// String formats the params as [RunParams.Redacted] does, so logging a
+// RunParams with %v or %+v prints only IDs, keys, file names, and the query's
+// length.
+func (p RunParams) String() string {
+	type plain RunParams // drops the String method to avoid recursion
+	return fmt.Sprintf("%+v", plain(p.Redacted()))
+}
+
+// redactValues returns a copy of m with the same keys and redacted values.
+func redactValues(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]any, len(m))
+	for k := range m {
+		out[k] = redactedText
+	}
+	return out
 }
 
 // Run triggers a workflow execution.
-func (s *WorkflowService) Run(ctx context.Context, params RunParams) (*RunResponse, error) {
+func (s *WorkflowService) Run(ctx context.Context, params RunParams, opts ...RequestOption) (*RunResponse, error) {
+	params.AdditionalParams = s.client.withContextMetadata(ctx, params.AdditionalParams)
+
 	var resp RunResponse
-	if err := s.client.do(ctx, "POST", "/workflow-requests/run", params, &resp); err != nil {
+	if err := s.client.do(ctx, "POST", "/workflow-requests/run", params, &resp, opts...); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
+// RetryChild re-runs the child execution at childIndex (its
+// [ChildExecution.Index]) of a fan-out parent, leaving the other children as
+// they are. The returned WorkflowRequestID is the new child's.
+//
+// The parent's execution tree is fetched first: an index that matches no
+// child is rejected with a [*ValidationError], and a child that has not
+// failed with a [*ConflictError], before anything is retried.
+func (s *WorkflowService) RetryChild(ctx context.Context, parentRequestID string, childIndex int) (*RunResponse, error) {
+	if childIndex < 0 {
+		return nil, &ValidationError{Field: "childIndex", Message: fmt.Sprintf("must be >= 0, got %d", childIndex)}
+	}
+
+	tree, err := s.GetExecutionTree(ctx, parentRequestID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRetryableChild(tree.ExecutionTree, childIndex); err != nil {
+		return nil, err
+	}
+
+	var resp RunResponse
+	path := fmt.Sprintf("/workflow-requests/%s/children/%d/retry", parentRequestID, childIndex)
+	if err := s.client.do(ctx, "POST", path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// checkRetryableChild reports whether the parent's child at index exists and
+// has failed. If the tree lists only some of a node's children, an index it
+// does not list is left for the server to judge.
+func checkRetryableChild(parent ExecutionTree, index int) error {
+	count, partial := 0, false
+	for _, n := range parent.Nodes {
+		for _, child := range n.ChildExecutions {
+			if child.Index != index {
+				continue
+			}
+			if Status(child.Status) != StatusFailed {
+				msg := fmt.Sprintf("child %d has status %q, only failed children can be retried", index, child.Status)
+				return &ConflictError{APIError{StatusCode: http.StatusConflict, Message: msg}}
+			}
+			return nil
+		}
+		count += len(n.ChildExecutions)
+		if n.HasMoreChildren != nil && *n.HasMoreChildren {
+			if n.TotalChildren == nil || index < *n.TotalChildren {
+				partial = true
+			}
+		}
+	}
+	if partial {
+		return nil
+	}
+	return &ValidationError{Field: "childIndex", Message: fmt.Sprintf("no child with index %d (parent has %d children)", index, count)}
+}
+
+// EstimateCost returns the expected cost of running params without starting
+// a run. It returns an [*EstimateUnavailableError] if the server cannot
+// estimate this workflow.
+func (s *WorkflowService) EstimateCost(ctx context.Context, params RunParams) (*CostEstimate, error) {
+	var resp CostEstimate
+	if err := s.client.do(ctx, "POST", "/workflow-requests/estimate", params, &resp); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnprocessableEntity {
+			return nil, &EstimateUnavailableError{APIError: *apiErr}
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RunOption customizes the params of [WorkflowService.RunLatest].
+type RunOption func(*RunParams)
+
+// WithChatID runs in an existing chat instead of creating one.
+func WithChatID(chatID string) RunOption {
+	return func(p *RunParams) { p.ChatID = chatID }
+}
+
+// WithEntryNodes runs from the given entry nodes instead of the version's
+// sole entry node.
+func WithEntryNodes(nodeIDs ...string) RunOption {
+	return func(p *RunParams) { p.EntryNodeIDs = nodeIDs }
+}
+
+// WithFiles attaches files to the run.
+func WithFiles(files ...WorkflowRequestFile) RunOption {
+	return func(p *RunParams) { p.Files = files }
+}
+
+// WithAdditionalParams sets RunParams.AdditionalParams.
+func WithAdditionalParams(params map[string]any) RunOption {
+	return func(p *RunParams) { p.AdditionalParams = params }
+}
+
+// WithRunMetadata sets RunParams.Metadata.
+func WithRunMetadata(metadata map[string]any) RunOption {
+	return func(p *RunParams) { p.Metadata = metadata }
+}
+
+// RunLatest runs the highest-numbered published version of a workflow from
+// its sole entry node. It returns a [*ValidationError] if the workflow has no
+// published version, or if the version has no entry node or several; pass
+// [WithEntryNodes] to choose among several. Unless [WithChatID] is given, a
+// new chat is created for the workflow.
+func (s *WorkflowService) RunLatest(ctx context.Context, workflowID string, query string, opts ...RunOption) (*RunResponse, error) {
+	params := RunParams{Query: query}
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	versions, err := s.ListVersions(ctx, workflowID, &ListVersionsParams{Status: "published", Order: "desc"})
+	if err != nil {
+		return nil, err
+	}
+	if len(versions.Versions) == 0 {
+		return nil, &ValidationError{Field: "workflowID", Message: fmt.Sprintf("workflow %s has no published version", workflowID)}
+	}
+	latest := versions.Versions[0]
+	params.WorkflowVersionID = latest.ID
+
+	if len(params.EntryNodeIDs) == 0 {
+		entry, err := s.GetEntryNodes(ctx, latest.ID)
+		if err != nil {
+			return nil, err
+		}
+		node, err := entry.Single()
+		if err != nil {
+			return nil, err
+		}
+		params.EntryNodeIDs = []string{node.ID}
+	}
+
+	if params.ChatID == "" {
+		chat, err := s.client.Chats.Create(ctx, CreateChatParams{Name: latest.Name, ResourceID: workflowID})
+		if err != nil {
+			return nil, err
+		}
+		params.ChatID = chat.ID
+	}
+
+	return s.Run(ctx, params)
+}
+
 // Listen opens an SSE stream for real-time execution updates.
-// The caller must call [SSEIter.Close] when done.
+// The caller must call [SSEIter.Close] when done. When reconnecting in a
+// loop, use [IsRetryable] to decide whether to try again; an [*AuthError] or
+// [*ForbiddenError] will not succeed on retry.
 func (s *WorkflowService) Listen(ctx context.Context, workflowRequestID string) (*SSEIter, error) {
 	return s.client.streamSSE(ctx, "/workflow-requests/"+workflowRequestID+"/listen")
 }
 
+// ListenFrom is like [WorkflowService.Listen] but resumes after lastEventID
+// (see [SSEIter.LastEventID]) by sending it as the Last-Event-ID header, so
+// the server can replay events missed while disconnected. Servers without
+// replay support stream new events only. An empty lastEventID is the same
+// as Listen.
+func (s *WorkflowService) ListenFrom(ctx context.Context, workflowRequestID, lastEventID string) (*SSEIter, error) {
+	var opts []RequestOption
+	if lastEventID != "" {
+		opts = append(opts, WithHeader("Last-Event-ID", lastEventID))
+	}
+	return s.client.streamSSE(ctx, "/workflow-requests/"+workflowRequestID+"/listen", opts...)
+}
+
 // GetExecutionTree returns the complete execution hierarchy.
-func (s *WorkflowService) GetExecutionTree(ctx context.Context, workflowRequestID string) (*ExecutionTreeResponse, error) {
+func (s *WorkflowService) GetExecutionTree(ctx context.Context, workflowRequestID string, opts ...RequestOption) (*ExecutionTreeResponse, error) {
 	var resp ExecutionTreeResponse
-	if err := s.client.do(ctx, "GET", "/workflow-requests/"+workflowRequestID+"/execution-tree", nil, &resp); err != nil {
+	if err := s.client.do(ctx, "GET", "/workflow-requests/"+workflowRequestID+"/execution-tree", nil, &resp, opts...); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
+// GetExecutionTrees fetches the execution trees of several requests
+// concurrently, using a bounded pool of workers (see [WithConcurrency]). The
+// result is keyed by request ID and holds only the trees that were fetched.
+// A failed fetch, including a [*NotFoundError], does not abort the others;
+// all failures are joined into the returned error.
+func (s *WorkflowService) GetExecutionTrees(ctx context.Context, workflowRequestIDs []string, opts ...BatchOption) (map[string]*ExecutionTreeResponse, error) {
+	var ids []string
+	seen := make(map[string]bool, len(workflowRequestIDs))
+	for _, id := range workflowRequestIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	fetched := make([]*ExecutionTreeResponse, len(ids))
+	err := runBounded(ctx, len(ids), batchConcurrency(opts), func(i int) error {
+		tree, err := s.GetExecutionTree(ctx, ids[i])
+		if err != nil {
+			return fmt.Errorf("splox: get execution tree %s: %w", ids[i], err)
+		}
+		fetched[i] = tree
+		return nil
+	})
+
+	trees := make(map[string]*ExecutionTreeResponse, len(ids))
+	for i, tree := range fetched {
+		if tree != nil {
+			trees[ids[i]] = tree
+		}
+	}
+	return trees, err
+}
+
+// StreamExecutionTree fetches the execution tree and calls fn for each
+// top-level node as it is decoded, so very large trees never have to be held
+// in memory at once. If fn returns an error, streaming stops and that error
+// is returned.
+func (s *WorkflowService) StreamExecutionTree(ctx context.Context, workflowRequestID string, fn func(ExecutionNode) error) error {
+	decode := bodyDecoder(func(r io.Reader) error {
+		return streamTreeNodes(s.client.newDecoder(r), fn)
+	})
+	return s.client.do(ctx, "GET", "/workflow-requests/"+workflowRequestID+"/execution-tree", nil, decode)
+}
+
+// streamTreeNodes walks {"execution_tree": {"nodes": [...]}} token by token,
+// decoding one node at a time and skipping every other field.
+func streamTreeNodes(dec *json.Decoder, fn func(ExecutionNode) error) error {
+	decodeErr := func(err error) error { return fmt.Errorf("splox: decode response: %w", err) }
+
+	return walkObject(dec, func(key string) error {
+		if key != "execution_tree" {
+			return skipValue(dec)
+		}
+		return walkObject(dec, func(key string) error {
+			if key != "nodes" {
+				return skipValue(dec)
+			}
+			tok, err := dec.Token()
+			if err != nil {
+				return decodeErr(err)
+			}
+			if tok == nil {
+				return nil
+			}
+			if d, ok := tok.(json.Delim); !ok || d != '[' {
+				return decodeErr(fmt.Errorf("expected array for nodes, got %v", tok))
+			}
+			for dec.More() {
+				var node ExecutionNode
+				if err := dec.Decode(&node); err != nil {
+					return decodeErr(err)
+				}
+				if err := fn(node); err != nil {
+					return err
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				return decodeErr(err)
+			}
+			return nil
+		})
+	})
+}
+
+// walkObject consumes a JSON object from dec, calling field for each key with
+// the decoder positioned at the key's value. field must consume the value.
+func walkObject(dec *json.Decoder, field func(key string) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("splox: decode response: %w", err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return fmt.Errorf("splox: decode response: expected object, got %v", tok)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("splox: decode response: %w", err)
+		}
+		if err := field(tok.(string)); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("splox: decode response: %w", err)
+	}
+	return nil
+}
+
+// skipValue consumes and discards the next JSON value.
+func skipValue(dec *json.Decoder) error {
+	var discard json.RawMessage
+	if err := dec.Decode(&discard); err != nil {
+		return fmt.Errorf("splox: decode response: %w", err)
+	}
+	return nil
+}
+
 // HistoryParams are optional parameters for [WorkflowService.GetHistory].
 type HistoryParams struct {
 	Limit  int
@@ -122,8 +551,8 @@ type HistoryParams struct {
 func (s *WorkflowService) GetHistory(ctx context.Context, workflowRequestID string, params *HistoryParams) (*HistoryResponse, error) {
 	v := url.Values{}
 	if params != nil {
-		if params.Limit > 0 {
-			v.Set("limit", fmt.Sprintf("%d", params.Limit))
+		if err := setLimit(v, params.Limit); err != nil {
+			return nil, err
 		}
 		if params.Cursor != "" {
 			v.Set("cursor", params.Cursor)
@@ -140,14 +569,170 @@ func (s *WorkflowService) GetHistory(ctx context.Context, workflowRequestID stri
 	return &resp, nil
 }
 
+// ListNodeExecutions returns every node execution for a workflow request in
+// creation order, one page at a time. Unlike [WorkflowService.GetExecutionTree]
+// the result is flat: child executions are not nested. Only Limit, Cursor and
+// Status (a node execution status) from params are used.
+func (s *WorkflowService) ListNodeExecutions(ctx context.Context, workflowRequestID string, params *ListParams) (*NodeExecutionListResponse, error) {
+	v := url.Values{}
+	if params != nil {
+		if err := setLimit(v, params.Limit); err != nil {
+			return nil, err
+		}
+		if params.Cursor != "" {
+			v.Set("cursor", params.Cursor)
+		}
+		if params.Status != "" {
+			v.Set("status", params.Status)
+		}
+	}
+
+	var resp NodeExecutionListResponse
+	if err := s.client.do(ctx, "GET", addParams("/workflow-requests/"+workflowRequestID+"/node-executions", v), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListRequestsParams are optional parameters for [WorkflowService.SearchRequests].
+type ListRequestsParams struct {
+	Limit             int
+	Cursor            string
+	WorkflowVersionID string // only requests for this version
+	Status            string // only requests with this status
+}
+
+// SearchRequests returns workflow requests whose metadata matches every
+// key/value pair in metadataFilter. Filtering happens server-side.
+func (s *WorkflowService) SearchRequests(ctx context.Context, metadataFilter map[string]string, params *ListRequestsParams) (*HistoryResponse, error) {
+	v := url.Values{}
+	for k, val := range metadataFilter {
+		v.Set("metadata."+k, val)
+	}
+	if params != nil {
+		if err := setLimit(v, params.Limit); err != nil {
+			return nil, err
+		}
+		if params.Cursor != "" {
+			v.Set("cursor", params.Cursor)
+		}
+		if params.WorkflowVersionID != "" {
+			v.Set("workflow_version_id", params.WorkflowVersionID)
+		}
+		if params.Status != "" {
+			v.Set("status", params.Status)
+		}
+	}
+
+	var resp HistoryResponse
+	if err := s.client.do(ctx, "GET", addParams("/workflow-requests/search", v), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // Stop cancels a running workflow execution.
 func (s *WorkflowService) Stop(ctx context.Context, workflowRequestID string) error {
 	return s.client.do(ctx, "POST", "/workflow-requests/"+workflowRequestID+"/stop", nil, nil)
 }
 
+// StopAll stops every in-progress request for a workflow version, using a
+// bounded pool of workers (see [WithConcurrency]). It returns how many were
+// stopped. A failed stop does not abort the others; all failures are joined
+// into the returned error.
+func (s *WorkflowService) StopAll(ctx context.Context, workflowVersionID string, opts ...BatchOption) (int, error) {
+	var running []string
+	params := &ListRequestsParams{
+		Limit:             maxPageLimit,
+		WorkflowVersionID: workflowVersionID,
+		Status:            string(StatusInProgress),
+	}
+	for {
+		resp, err := s.SearchRequests(ctx, nil, params)
+		if err != nil {
+			return 0, err
+		}
+		for _, req := range resp.Data {
+			running = append(running, req.ID)
+		}
+		if !resp.HasNextPage() {
+			break
+		}
+		params.Cursor = resp.PageCursor()
+	}
+
+	var stopped atomic.Int64
+	err := runBounded(ctx, len(running), batchConcurrency(opts), func(i int) error {
+		if err := s.Stop(ctx, running[i]); err != nil {
+			return fmt.Errorf("splox: stop %s: %w", running[i], err)
+		}
+		stopped.Add(1)
+		return nil
+	})
+	return int(stopped.Load()), err
+}
+
+// waitConfig holds settings for [WorkflowService.RunAndWait].
+type waitConfig struct {
+	errorOnFailure bool
+}
+
+// WaitOption configures [WorkflowService.RunAndWait].
+type WaitOption func(*waitConfig)
+
+// WithErrorOnFailure makes RunAndWait return an [*ExecutionError] alongside
+// the execution tree when the run ends with status "failed".
+func WithErrorOnFailure() WaitOption {
+	return func(c *waitConfig) { c.errorOnFailure = true }
+}
+
 // RunAndWait triggers a workflow and blocks until it reaches a terminal state.
-// It returns the full execution tree on completion.
-func (s *WorkflowService) RunAndWait(ctx context.Context, params RunParams, timeout time.Duration) (*ExecutionTreeResponse, error) {
+// It returns the full execution tree on completion, or an [*ExecutionError]
+// if the stream reports an error event. A failed run returns the tree and a
+// nil error unless [WithErrorOnFailure] is given.
+func (s *WorkflowService) RunAndWait(ctx context.Context, params RunParams, timeout time.Duration, opts ...WaitOption) (*ExecutionTreeResponse, error) {
+	return s.runAndWait(ctx, params, timeout, nil, opts)
+}
+
+// Progress is a snapshot of a run's progress reported by
+// [WorkflowService.RunAndWaitProgress]. TotalNodes counts every node in the
+// workflow version, so a run that skips branches may finish with
+// CompletedNodes below TotalNodes.
+type Progress struct {
+	CompletedNodes   int    // distinct nodes that reached a terminal status
+	TotalNodes       int    // nodes in the workflow version
+	CurrentNodeLabel string // label of the node that most recently started
+}
+
+// RunAndWaitProgress is like [WorkflowService.RunAndWait] but calls fn with
+// the run's progress, computed from node_execution events. fn is called only
+// when the progress changes, so text deltas and repeated node updates don't
+// trigger it. It fetches the workflow version first to count its nodes. A
+// nil fn is rejected with a [*ValidationError] before anything is sent.
+func (s *WorkflowService) RunAndWaitProgress(ctx context.Context, params RunParams, timeout time.Duration, fn func(Progress), opts ...WaitOption) (*ExecutionTreeResponse, error) {
+	if fn == nil {
+		return nil, &ValidationError{Field: "fn", Message: "progress callback must not be nil"}
+	}
+	version, err := s.GetVersion(ctx, params.WorkflowVersionID)
+	if err != nil {
+		return nil, err
+	}
+	t := newProgressTracker(version.Nodes)
+	return s.runAndWait(ctx, params, timeout, func(ev SSEEvent) {
+		if p, changed := t.update(ev.NodeExecution); changed {
+			fn(p)
+		}
+	}, opts)
+}
+
+// runAndWait implements RunAndWait, passing each non-keepalive event to
+// onEvent if it is non-nil.
+func (s *WorkflowService) runAndWait(ctx context.Context, params RunParams, timeout time.Duration, onEvent func(SSEEvent), opts []WaitOption) (*ExecutionTreeResponse, error) {
+	var cfg waitConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	result, err := s.Run(ctx, params)
 	if err != nil {
 		return nil, err
@@ -163,30 +748,117 @@ func (s *WorkflowService) RunAndWait(ctx context.Context, params RunParams, time
 	}
 	defer iter.Close()
 
-	terminal := map[string]bool{
-		"completed": true,
-		"failed":    true,
-		"stopped":   true,
-	}
-
 	for iter.Next() {
 		ev := iter.Event()
-		if ev.WorkflowRequest != nil && terminal[ev.WorkflowRequest.Status] {
-			return s.GetExecutionTree(ctx, result.WorkflowRequestID)
+		if ev.IsError() {
+			return nil, &ExecutionError{WorkflowRequestID: result.WorkflowRequestID, Message: ev.errorMessage()}
+		}
+		if onEvent != nil && !ev.IsKeepalive {
+			onEvent(ev)
+		}
+		if ev.WorkflowRequest != nil && Status(ev.WorkflowRequest.Status).IsTerminal() {
+			return s.executionResult(ctx, result.WorkflowRequestID, cfg)
 		}
 	}
 
+	// Check if the wait timed out (as opposed to the caller cancelling ctx)
+	if waitCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		return nil, &TimeoutError{Message: fmt.Sprintf("workflow did not complete within %s", timeout)}
+	}
+
 	if err := iter.Err(); err != nil {
 		return nil, err
 	}
 
-	// Check if context timed out
-	if waitCtx.Err() != nil {
-		return nil, &TimeoutError{Message: fmt.Sprintf("workflow did not complete within %s", timeout)}
+	// Stream ended without terminal status — fetch tree anyway
+	return s.executionResult(ctx, result.WorkflowRequestID, cfg)
+}
+
+// progressTracker folds node_execution events into a [Progress].
+type progressTracker struct {
+	labels    map[string]string // node ID to label
+	completed map[string]bool
+	last      Progress
+}
+
+func newProgressTracker(nodes []Node) *progressTracker {
+	labels := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		labels[n.ID] = n.Label
 	}
+	return &progressTracker{
+		labels:    labels,
+		completed: map[string]bool{},
+		last:      Progress{TotalNodes: len(nodes)},
+	}
+}
 
-	// Stream ended without terminal status — fetch tree anyway
-	return s.GetExecutionTree(ctx, result.WorkflowRequestID)
+// update applies ne, which may be nil, and reports the new progress and
+// whether it differs from the previous one.
+func (t *progressTracker) update(ne *NodeExecution) (Progress, bool) {
+	if ne == nil {
+		return t.last, false
+	}
+	p := t.last
+	if Status(ne.Status).IsTerminal() {
+		t.completed[ne.NodeID] = true
+		p.CompletedNodes = len(t.completed)
+	} else if label := t.labels[ne.NodeID]; label != "" {
+		p.CurrentNodeLabel = label
+	}
+	if p == t.last {
+		return p, false
+	}
+	t.last = p
+	return p, true
+}
+
+// executionResult fetches the execution tree of a finished run and, if
+// cfg.errorOnFailure is set and the run failed, pairs it with an
+// [*ExecutionError] describing the first failed node.
+func (s *WorkflowService) executionResult(ctx context.Context, workflowRequestID string, cfg waitConfig) (*ExecutionTreeResponse, error) {
+	tree, err := s.GetExecutionTree(ctx, workflowRequestID)
+	if err != nil || !cfg.errorOnFailure || Status(tree.ExecutionTree.Status) != StatusFailed {
+		return tree, err
+	}
+	return tree, tree.ExecutionTree.failure()
+}
+
+// WaitForStatus blocks until the request reaches target or any terminal
+// status and returns the request as last reported on its event stream. It
+// returns an [*ExecutionError] if the stream reports an error event, and a
+// [*TimeoutError] if neither happens within timeout.
+func (s *WorkflowService) WaitForStatus(ctx context.Context, workflowRequestID string, target Status, timeout time.Duration) (*WorkflowRequest, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	iter, err := s.Listen(waitCtx, workflowRequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	for iter.Next() {
+		ev := iter.Event()
+		if ev.IsError() {
+			return nil, &ExecutionError{WorkflowRequestID: workflowRequestID, Message: ev.errorMessage()}
+		}
+		req := ev.WorkflowRequest
+		if req == nil {
+			continue
+		}
+		if status := Status(req.Status); status == target || status.IsTerminal() {
+			return req, nil
+		}
+	}
+
+	if waitCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		return nil, &TimeoutError{Message: fmt.Sprintf("request did not reach status %q within %s", target, timeout)}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("splox: stream ended before request %s reached status %q", workflowRequestID, target)
 }
 
 // --- Secrets ---
@@ -210,6 +882,26 @@ func (s *WorkflowService) ListSecrets(ctx context.Context, workflowID string, pa
 	return resp, nil
 }
 
+// GetSecretParams are optional parameters for [WorkflowService.GetSecret].
+type GetSecretParams struct {
+	EndUserID string
+}
+
+// GetSecret returns the metadata of a single secret (the value is never
+// returned). It returns a [*NotFoundError] if the key does not exist.
+func (s *WorkflowService) GetSecret(ctx context.Context, workflowID, key string, params *GetSecretParams) (*WorkflowSecretMetadata, error) {
+	v := url.Values{}
+	if params != nil && params.EndUserID != "" {
+		v.Set("end_user_id", params.EndUserID)
+	}
+
+	var resp WorkflowSecretMetadata
+	if err := s.client.do(ctx, "GET", addParams("/workflows/"+workflowID+"/secrets/"+key, v), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // SetEnvSecret creates or updates an environment-variable secret.
 func (s *WorkflowService) SetEnvSecret(ctx context.Context, workflowID string, params SetEnvSecretParams) (*SecretActionResponse, error) {
 	var resp SecretActionResponse
@@ -219,6 +911,47 @@ func (s *WorkflowService) SetEnvSecret(ctx context.Context, workflowID string, p
 	return &resp, nil
 }
 
+// SetSecretsParams are optional parameters for [WorkflowService.SetEnvSecrets].
+type SetSecretsParams struct {
+	EndUserID string
+}
+
+// SetEnvSecrets creates or updates several environment-variable secrets in
+// one call. The API only accepts single writes, so the secrets are sent
+// concurrently using a bounded pool of workers (see [WithConcurrency]). The
+// response lists the keys that were written, sorted, and Success is true only
+// if all of them were; failures are joined into the returned error.
+func (s *WorkflowService) SetEnvSecrets(ctx context.Context, workflowID string, secrets map[string]string, params *SetSecretsParams, opts ...BatchOption) (*SecretActionResponse, error) {
+	var endUserID *string
+	if params != nil && params.EndUserID != "" {
+		endUserID = &params.EndUserID
+	}
+
+	keys := make([]string, 0, len(secrets))
+	for key := range secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	written := make([]bool, len(keys))
+	err := runBounded(ctx, len(keys), batchConcurrency(opts), func(i int) error {
+		key := keys[i]
+		if _, err := s.SetEnvSecret(ctx, workflowID, SetEnvSecretParams{Key: key, Value: secrets[key], EndUserID: endUserID}); err != nil {
+			return fmt.Errorf("splox: set secret %s: %w", key, err)
+		}
+		written[i] = true
+		return nil
+	})
+
+	var done []string
+	for i, key := range keys {
+		if written[i] {
+			done = append(done, key)
+		}
+	}
+	return &SecretActionResponse{Success: err == nil, Keys: done}, err
+}
+
 // SetFileSecret creates or updates a file-type secret (S3 URL).
 func (s *WorkflowService) SetFileSecret(ctx context.Context, workflowID string, params SetFileSecretParams) (*SecretActionResponse, error) {
 	var resp SecretActionResponse